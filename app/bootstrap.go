@@ -14,11 +14,17 @@ import (
 	"github.com/joho/godotenv"
 
 	"store-serverless/internal/auth"
+	"store-serverless/internal/cart"
+	"store-serverless/internal/category"
 	"store-serverless/internal/db"
 	"store-serverless/internal/maintenance"
 	"store-serverless/internal/media"
 	"store-serverless/internal/observability"
+	"store-serverless/internal/order"
+	"store-serverless/internal/payment"
 	"store-serverless/internal/product"
+	"store-serverless/internal/promotion"
+	"store-serverless/internal/review"
 )
 
 type Options struct {
@@ -51,7 +57,8 @@ func Build(options Options) (*Runtime, error) {
 		return nil, err
 	}
 
-	if err := observability.InitSentry(os.Getenv("SENTRY_DSN"), envOrDefault("APP_ENV", "development")); err != nil {
+	appEnv := envOrDefault("APP_ENV", "development")
+	if err := observability.InitSentry(os.Getenv("SENTRY_DSN"), appEnv); err != nil {
 		logger.Error("init_sentry_failed", map[string]any{"error": err.Error()})
 	}
 
@@ -101,13 +108,38 @@ func Build(options Options) (*Runtime, error) {
 	}
 
 	productRepo := product.NewRepository(database)
+	cleanupHandler.WithProductTrash(productRepo, envDaysOrDefault("PRODUCT_TRASH_RETENTION_DAYS", 30))
 	cloudinaryClient, err := media.NewCloudinary(cloudinaryURL)
 	if err != nil {
 		_ = database.Close()
 		return nil, fmt.Errorf("init cloudinary: %w", err)
 	}
 	productHandler := product.NewHandler(productRepo, cloudinaryClient)
+	productHandler.WithConcurrencyConfig(EnvBoolOrDefault("PRODUCT_REQUIRE_IF_MATCH", false))
+	productHandler.WithImportConfig(
+		envIntOrDefault("PRODUCT_IMPORT_MAX_ROWS", 1000),
+		envIntOrDefault("PRODUCT_IMPORT_CONCURRENCY", 4),
+	)
 	mediaUploadHandler := media.NewUploadHandler(cloudinaryClient)
+	categoryHandler := category.NewHandler(category.NewRepository(database))
+	promotionHandler := promotion.NewHandler(promotion.NewRepository(database))
+	reviewHandler := review.NewHandler(review.NewRepository(database))
+	cartRepo := cart.NewRepository(database)
+	cleanupHandler.WithCarts(cartRepo)
+	cartService := cart.NewService(cartRepo, productRepo, envOrDefault("CART_TOKEN_SECRET", jwtSecret))
+	cartService.WithTTL(envHoursOrDefault("CART_TTL_HOURS", 168))
+	cartHandler := cart.NewHandler(cartService)
+	orderService := order.NewService(order.NewRepository(database), cartService, envOrDefault("ORDER_TOKEN_SECRET", jwtSecret))
+	orderHandler := order.NewHandler(orderService)
+	reviewHandler.WithPurchaseVerifier(orderService)
+	paymentProvider, err := newPaymentProvider(appEnv, jwtSecret)
+	if err != nil {
+		_ = database.Close()
+		return nil, fmt.Errorf("init payment provider: %w", err)
+	}
+	if paymentProvider == nil {
+		logger.Info("payments_disabled", map[string]any{"reason": "PAYMENT_PROVIDER is not set"})
+	}
 
 	loginLimiter := auth.NewLoginRateLimiter(
 		authRepo,
@@ -122,10 +154,70 @@ func Build(options Options) (*Runtime, error) {
 	mux.HandleFunc("GET /internal/maintenance/cleanup", cleanupHandler.Handle)
 	mux.HandleFunc("POST /internal/maintenance/cleanup", cleanupHandler.Handle)
 	mux.HandleFunc("GET /health", healthHandler(database))
-	mux.HandleFunc("GET /products", productHandler.ListProducts)
+	mux.Handle("GET /products", auth.OptionalMiddleware(jwtSecret, http.HandlerFunc(productHandler.ListProducts)))
+	mux.Handle("GET /products/search", auth.OptionalMiddleware(jwtSecret, http.HandlerFunc(productHandler.SearchProducts)))
+	mux.Handle("GET /products/{id}", auth.OptionalMiddleware(jwtSecret, http.HandlerFunc(productHandler.GetProduct)))
+	mux.Handle("GET /products/{id}/related", auth.OptionalMiddleware(jwtSecret, http.HandlerFunc(productHandler.ListRelated)))
+	mux.HandleFunc("GET /products/{id}/reviews", reviewHandler.ListProductReviews)
+	mux.HandleFunc("POST /products/{id}/reviews", reviewHandler.SubmitReview)
+	mux.Handle("GET /products/export", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.ExportProducts)))
+	mux.Handle("POST /products/import", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.ImportProducts)))
+	mux.Handle("GET /products/translations/missing", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.ListMissingTranslations)))
+	mux.Handle("GET /products/trash", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.ListTrash)))
+	mux.Handle("POST /products/{id}/restore", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.RestoreProduct)))
+	mux.Handle("GET /products/{id}/price-history", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.ListPriceHistory)))
+	mux.Handle("GET /products/{id}/history", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.ListRevisions)))
+	mux.Handle("POST /products/{id}/history/{revisionID}/rollback", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.RollbackProduct)))
 	mux.Handle("POST /products", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.CreateProduct)))
 	mux.Handle("PUT /products/{id}", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.UpdateProduct)))
+	mux.Handle("PATCH /products/{id}", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.PatchProduct)))
 	mux.Handle("DELETE /products/{id}", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.DeleteProduct)))
+	mux.Handle("POST /products/{id}/stock", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.AdjustStock)))
+	mux.Handle("GET /products/{id}/stock/movements", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.ListStockMovements)))
+	mux.Handle("POST /products/{id}/variants", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.CreateVariant)))
+	mux.Handle("PUT /products/{id}/variants/{variantID}", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.UpdateVariant)))
+	mux.Handle("DELETE /products/{id}/variants/{variantID}", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.DeleteVariant)))
+	mux.Handle("POST /products/{id}/images", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.AddImage)))
+	mux.Handle("PUT /products/{id}/images/order", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.ReorderImages)))
+	mux.Handle("DELETE /products/{id}/images/{imageID}", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.DeleteImage)))
+	mux.Handle("GET /products/{id}/translations", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.ListTranslations)))
+	mux.Handle("PUT /products/{id}/translations/{locale}", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.SaveTranslation)))
+	mux.Handle("DELETE /products/{id}/translations/{locale}", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.DeleteTranslation)))
+	mux.Handle("GET /products/{id}/relations", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.ListRelations)))
+	mux.Handle("PUT /products/{id}/relations", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.SetRelations)))
+	mux.Handle("PUT /products/{id}/categories", auth.Middleware(jwtSecret, http.HandlerFunc(categoryHandler.SetProductCategories)))
+	mux.HandleFunc("GET /categories", categoryHandler.ListCategories)
+	mux.Handle("POST /categories", auth.Middleware(jwtSecret, http.HandlerFunc(categoryHandler.CreateCategory)))
+	mux.Handle("PUT /categories/{id}", auth.Middleware(jwtSecret, http.HandlerFunc(categoryHandler.UpdateCategory)))
+	mux.Handle("DELETE /categories/{id}", auth.Middleware(jwtSecret, http.HandlerFunc(categoryHandler.DeleteCategory)))
+	mux.HandleFunc("POST /cart", cartHandler.CreateCart)
+	mux.HandleFunc("GET /cart", cartHandler.GetCart)
+	mux.HandleFunc("POST /cart/items", cartHandler.AddItem)
+	mux.HandleFunc("PUT /cart/items/{itemID}", cartHandler.UpdateItem)
+	mux.HandleFunc("DELETE /cart/items/{itemID}", cartHandler.RemoveItem)
+	mux.HandleFunc("POST /orders", orderHandler.Checkout)
+	mux.Handle("GET /orders", auth.Middleware(jwtSecret, http.HandlerFunc(orderHandler.ListOrders)))
+	mux.Handle("GET /orders/{id}", auth.OptionalMiddleware(jwtSecret, http.HandlerFunc(orderHandler.GetOrder)))
+	mux.Handle("POST /orders/{id}/status", auth.Middleware(jwtSecret, http.HandlerFunc(orderHandler.TransitionOrder)))
+	if paymentProvider != nil {
+		paymentHandler := payment.NewHandler(payment.NewService(payment.NewRepository(database), paymentProvider, orderService))
+		mux.Handle("POST /orders/{id}/payment", auth.OptionalMiddleware(jwtSecret, http.HandlerFunc(paymentHandler.CreatePayment)))
+		mux.Handle("POST /orders/{id}/payment/capture", auth.Middleware(jwtSecret, http.HandlerFunc(paymentHandler.CapturePayment)))
+		mux.Handle("POST /orders/{id}/payment/refund", auth.Middleware(jwtSecret, http.HandlerFunc(paymentHandler.RefundPayment)))
+		mux.HandleFunc("POST /payments/webhook", paymentHandler.Webhook)
+	}
+	mux.HandleFunc("GET /attributes", productHandler.ListAttributes)
+	mux.Handle("POST /attributes", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.CreateAttribute)))
+	mux.Handle("PUT /attributes/{key}", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.UpdateAttribute)))
+	mux.Handle("DELETE /attributes/{key}", auth.Middleware(jwtSecret, http.HandlerFunc(productHandler.DeleteAttribute)))
+	mux.Handle("GET /reviews", auth.Middleware(jwtSecret, http.HandlerFunc(reviewHandler.ListReviews)))
+	mux.Handle("POST /reviews/{id}/approve", auth.Middleware(jwtSecret, http.HandlerFunc(reviewHandler.ApproveReview)))
+	mux.Handle("POST /reviews/{id}/reject", auth.Middleware(jwtSecret, http.HandlerFunc(reviewHandler.RejectReview)))
+	mux.Handle("DELETE /reviews/{id}", auth.Middleware(jwtSecret, http.HandlerFunc(reviewHandler.DeleteReview)))
+	mux.Handle("GET /promotions", auth.Middleware(jwtSecret, http.HandlerFunc(promotionHandler.ListPromotions)))
+	mux.Handle("POST /promotions", auth.Middleware(jwtSecret, http.HandlerFunc(promotionHandler.CreatePromotion)))
+	mux.Handle("PUT /promotions/{id}", auth.Middleware(jwtSecret, http.HandlerFunc(promotionHandler.UpdatePromotion)))
+	mux.Handle("DELETE /promotions/{id}", auth.Middleware(jwtSecret, http.HandlerFunc(promotionHandler.DeletePromotion)))
 	mux.Handle("POST /media/upload", auth.Middleware(jwtSecret, http.HandlerFunc(mediaUploadHandler.Upload)))
 
 	handler := observability.RecoverMiddleware(logger, observability.RequestLoggingMiddleware(logger, mux))
@@ -139,6 +231,33 @@ func Build(options Options) (*Runtime, error) {
 	}, nil
 }
 
+func newPaymentProvider(appEnv, jwtSecret string) (payment.Provider, error) {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("PAYMENT_PROVIDER")))
+	webhookSecret := strings.TrimSpace(os.Getenv("PAYMENT_WEBHOOK_SECRET"))
+	if appEnv == "development" {
+		if name == "" {
+			name = payment.FakeProviderName
+		}
+		if webhookSecret == "" {
+			webhookSecret = jwtSecret
+		}
+	} else {
+		if name == "" {
+			return nil, nil
+		}
+		if webhookSecret == "" || webhookSecret == jwtSecret {
+			return nil, fmt.Errorf("PAYMENT_WEBHOOK_SECRET must be set and differ from JWT_SECRET")
+		}
+	}
+
+	switch name {
+	case payment.FakeProviderName:
+		return payment.NewFake(webhookSecret), nil
+	default:
+		return nil, fmt.Errorf("unsupported payment provider %q", name)
+	}
+}
+
 func healthHandler(database *sql.DB) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
@@ -1,12 +1,15 @@
 package auth
 
 import (
+	"context"
 	"net/http"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
+type contextKey struct{}
+
 func Middleware(jwtSecret string, next http.Handler) http.Handler {
 	secret := []byte(jwtSecret)
 
@@ -17,31 +20,63 @@ func Middleware(jwtSecret string, next http.Handler) http.Handler {
 			return
 		}
 
-		parts := strings.SplitN(header, " ", 2)
-		if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
-			writeError(w, http.StatusUnauthorized, "invalid authorization format")
+		subject, message := verifyAccessToken(header, secret)
+		if message != "" {
+			writeError(w, http.StatusUnauthorized, message)
 			return
 		}
 
-		tokenStr := strings.TrimSpace(parts[1])
-		if tokenStr == "" {
-			writeError(w, http.StatusUnauthorized, "invalid authorization token")
-			return
-		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, subject)))
+	})
+}
 
-		claims := jwt.MapClaims{}
-		token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (any, error) {
-			return secret, nil
-		}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
-		if err != nil || !token.Valid {
-			writeError(w, http.StatusUnauthorized, "invalid or expired token")
+func OptionalMiddleware(jwtSecret string, next http.Handler) http.Handler {
+	secret := []byte(jwtSecret)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := strings.TrimSpace(r.Header.Get("Authorization"))
+		if header == "" {
+			next.ServeHTTP(w, r)
 			return
 		}
-		if tokenType, _ := claims["typ"].(string); tokenType != "access" {
-			writeError(w, http.StatusUnauthorized, "invalid token type")
+
+		subject, message := verifyAccessToken(header, secret)
+		if message != "" {
+			next.ServeHTTP(w, r)
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, subject)))
 	})
 }
+
+func Subject(ctx context.Context) (string, bool) {
+	subject, ok := ctx.Value(contextKey{}).(string)
+	return subject, ok
+}
+
+func verifyAccessToken(header string, secret []byte) (string, string) {
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return "", "invalid authorization format"
+	}
+
+	tokenStr := strings.TrimSpace(parts[1])
+	if tokenStr == "" {
+		return "", "invalid authorization token"
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (any, error) {
+		return secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return "", "invalid or expired token"
+	}
+	if tokenType, _ := claims["typ"].(string); tokenType != "access" {
+		return "", "invalid token type"
+	}
+
+	subject, _ := claims["sub"].(string)
+	return subject, ""
+}
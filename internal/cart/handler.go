@@ -0,0 +1,180 @@
+package cart
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+)
+
+const (
+	maxJSONBodyBytes = 4 << 10
+	tokenHeader      = "X-Cart-Token"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) CreateCart(w http.ResponseWriter, r *http.Request) {
+	session, err := h.service.Create(r.Context())
+	if err != nil {
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to create cart")
+		return
+	}
+
+	w.Header().Set(tokenHeader, session.Token)
+	writeJSON(w, http.StatusCreated, session)
+}
+
+func (h *Handler) GetCart(w http.ResponseWriter, r *http.Request) {
+	c, err := h.service.Get(r.Context(), r.Header.Get(tokenHeader))
+	if err != nil {
+		h.writeServiceError(w, err, "failed to get cart")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (h *Handler) AddItem(w http.ResponseWriter, r *http.Request) {
+	var input ItemInput
+	if !decodeBody(w, r, &input) {
+		return
+	}
+
+	input.ProductID = strings.TrimSpace(input.ProductID)
+	if _, err := uuid.Parse(input.ProductID); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product_id")
+		return
+	}
+	if input.VariantID != nil {
+		variantID := strings.TrimSpace(*input.VariantID)
+		if _, err := uuid.Parse(variantID); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid variant_id")
+			return
+		}
+		input.VariantID = &variantID
+	}
+	if !checkQuantity(w, input.Quantity) {
+		return
+	}
+
+	c, err := h.service.AddItem(r.Context(), r.Header.Get(tokenHeader), input)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to add cart item")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (h *Handler) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	itemID, ok := parseItemID(w, r)
+	if !ok {
+		return
+	}
+
+	var input QuantityInput
+	if !decodeBody(w, r, &input) {
+		return
+	}
+	if !checkQuantity(w, input.Quantity) {
+		return
+	}
+
+	c, err := h.service.UpdateItem(r.Context(), r.Header.Get(tokenHeader), itemID, input.Quantity)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to update cart item")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (h *Handler) RemoveItem(w http.ResponseWriter, r *http.Request) {
+	itemID, ok := parseItemID(w, r)
+	if !ok {
+		return
+	}
+
+	c, err := h.service.RemoveItem(r.Context(), r.Header.Get(tokenHeader), itemID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to remove cart item")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (h *Handler) writeServiceError(w http.ResponseWriter, err error, message string) {
+	switch {
+	case errors.Is(err, ErrInvalidToken):
+		writeError(w, http.StatusUnauthorized, "missing or invalid cart token")
+	case errors.Is(err, ErrCartNotFound):
+		writeError(w, http.StatusNotFound, "cart not found or expired")
+	case errors.Is(err, ErrItemNotFound):
+		writeError(w, http.StatusNotFound, "cart item not found")
+	case errors.Is(err, ErrProductUnavailable):
+		writeError(w, http.StatusNotFound, "product not found")
+	case errors.Is(err, ErrVariantRequired), errors.Is(err, ErrVariantNotFound):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrQuantityTooLarge):
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("quantity per item must be at most %d", maxLineQuantity))
+	case errors.Is(err, ErrTooManyItems):
+		writeError(w, http.StatusConflict, fmt.Sprintf("cart can hold at most %d items", maxCartItems))
+	case errors.Is(err, ErrInsufficientStock), errors.Is(err, ErrCurrencyMismatch):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, message)
+	}
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, target any) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return false
+	}
+	return true
+}
+
+func checkQuantity(w http.ResponseWriter, quantity int) bool {
+	if quantity < 1 || quantity > maxLineQuantity {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("quantity must be between 1 and %d", maxLineQuantity))
+		return false
+	}
+	return true
+}
+
+func parseItemID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	itemID := r.PathValue("itemID")
+	if _, err := uuid.Parse(itemID); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid item id")
+		return "", false
+	}
+	return itemID, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,51 @@
+package cart
+
+import (
+	"time"
+
+	"store-serverless/internal/money"
+)
+
+type Cart struct {
+	ID        string       `json:"id"`
+	Items     []Item       `json:"items"`
+	ItemCount int          `json:"item_count"`
+	Subtotal  money.Amount `json:"subtotal"`
+	Currency  string       `json:"currency"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+	ExpiresAt time.Time    `json:"expires_at"`
+}
+
+type Item struct {
+	ID                string            `json:"id"`
+	ProductID         string            `json:"product_id"`
+	VariantID         *string           `json:"variant_id"`
+	Quantity          int               `json:"quantity"`
+	Title             string            `json:"title"`
+	SKU               *string           `json:"sku"`
+	Options           map[string]string `json:"options,omitempty"`
+	ImageURL          string            `json:"image_url"`
+	UnitPrice         money.Amount      `json:"unit_price"`
+	LineTotal         money.Amount      `json:"line_total"`
+	Currency          string            `json:"currency"`
+	Available         bool              `json:"available"`
+	AvailableQuantity *int              `json:"available_quantity"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+}
+
+type ItemInput struct {
+	ProductID string  `json:"product_id"`
+	VariantID *string `json:"variant_id"`
+	Quantity  int     `json:"quantity"`
+}
+
+type QuantityInput struct {
+	Quantity int `json:"quantity"`
+}
+
+type Session struct {
+	Token string `json:"token"`
+	Cart  Cart   `json:"cart"`
+}
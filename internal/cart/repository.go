@@ -0,0 +1,289 @@
+package cart
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(ctx context.Context, ttl time.Duration) (Cart, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return Cart{}, fmt.Errorf("generate uuid v7: %w", err)
+	}
+
+	now := time.Now().UTC()
+	c, err := scanCart(r.db.QueryRowContext(ctx, `
+		INSERT INTO carts (id, created_at, updated_at, expires_at)
+		VALUES ($1, $2, $2, $3)
+		RETURNING `+cartColumns+`
+	`, id.String(), now, now.Add(ttl)))
+	if err != nil {
+		return Cart{}, fmt.Errorf("insert cart: %w", err)
+	}
+
+	c.Items = make([]Item, 0)
+	return c, nil
+}
+
+func (r *Repository) Get(ctx context.Context, id string) (Cart, error) {
+	c, err := scanCart(r.db.QueryRowContext(ctx, `
+		SELECT `+cartColumns+`
+		FROM carts
+		WHERE id = $1 AND expires_at > $2
+	`, id, time.Now().UTC()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Cart{}, ErrCartNotFound
+		}
+		return Cart{}, fmt.Errorf("get cart: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+itemColumns+`
+		FROM cart_items
+		WHERE cart_id = $1
+		ORDER BY created_at ASC, id ASC
+	`, id)
+	if err != nil {
+		return Cart{}, fmt.Errorf("query cart items: %w", err)
+	}
+	defer rows.Close()
+
+	c.Items = make([]Item, 0)
+	for rows.Next() {
+		item, err := scanItem(rows)
+		if err != nil {
+			return Cart{}, fmt.Errorf("scan cart item: %w", err)
+		}
+		c.Items = append(c.Items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return Cart{}, fmt.Errorf("iterate cart items: %w", err)
+	}
+
+	return c, nil
+}
+
+func (r *Repository) AddItem(ctx context.Context, cartID, productID string, variantID *string, quantity int, ttl time.Duration, check func(quantity int) error) error {
+	now := time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin add cart item tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := touchCart(ctx, tx, cartID, now, ttl); err != nil {
+		return err
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("generate uuid v7: %w", err)
+	}
+
+	var total int
+	var inserted bool
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO cart_items (id, cart_id, product_id, variant_id, quantity, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+		ON CONFLICT (cart_id, product_id, COALESCE(variant_id, '00000000-0000-0000-0000-000000000000'::uuid))
+		DO UPDATE SET quantity = cart_items.quantity + EXCLUDED.quantity, updated_at = EXCLUDED.updated_at
+		RETURNING quantity, xmax = 0
+	`, id.String(), cartID, productID, variantID, quantity, now).Scan(&total, &inserted); err != nil {
+		return fmt.Errorf("upsert cart item: %w", err)
+	}
+
+	if inserted {
+		var count int
+		if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM cart_items WHERE cart_id = $1`, cartID).Scan(&count); err != nil {
+			return fmt.Errorf("count cart items: %w", err)
+		}
+		if count > maxCartItems {
+			return ErrTooManyItems
+		}
+	}
+
+	if err := check(total); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit add cart item tx: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) UpdateItem(ctx context.Context, cartID, itemID string, quantity int, ttl time.Duration) error {
+	now := time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin update cart item tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := touchCart(ctx, tx, cartID, now, ttl); err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		UPDATE cart_items
+		SET quantity = $3, updated_at = $4
+		WHERE cart_id = $1 AND id = $2
+	`, cartID, itemID, quantity, now)
+	if err != nil {
+		return fmt.Errorf("update cart item: %w", err)
+	}
+	if err := requireAffected(res, "update cart item"); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit update cart item tx: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) RemoveItem(ctx context.Context, cartID, itemID string, ttl time.Duration) error {
+	now := time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin remove cart item tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := touchCart(ctx, tx, cartID, now, ttl); err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM cart_items WHERE cart_id = $1 AND id = $2`, cartID, itemID)
+	if err != nil {
+		return fmt.Errorf("delete cart item: %w", err)
+	}
+	if err := requireAffected(res, "delete cart item"); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit remove cart item tx: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteExpired(ctx context.Context, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+
+	res, err := r.db.ExecContext(ctx, `
+		WITH expired AS (
+			SELECT id
+			FROM carts
+			WHERE expires_at <= $1
+			ORDER BY expires_at ASC
+			LIMIT $2
+		)
+		DELETE FROM carts c
+		USING expired
+		WHERE c.id = expired.id
+	`, time.Now().UTC(), batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("delete expired carts: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("deleted carts rows affected: %w", err)
+	}
+
+	return affected, nil
+}
+
+func touchCart(ctx context.Context, tx *sql.Tx, cartID string, now time.Time, ttl time.Duration) error {
+	res, err := tx.ExecContext(ctx, `
+		UPDATE carts
+		SET updated_at = $2, expires_at = $3
+		WHERE id = $1 AND expires_at > $2
+	`, cartID, now, now.Add(ttl))
+	if err != nil {
+		return fmt.Errorf("touch cart: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("touch cart rows affected: %w", err)
+	}
+	if affected == 0 {
+		return ErrCartNotFound
+	}
+	return nil
+}
+
+func requireAffected(res sql.Result, action string) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("%s rows affected: %w", action, err)
+	}
+	if affected == 0 {
+		return ErrItemNotFound
+	}
+	return nil
+}
+
+const (
+	cartColumns = `id, created_at, updated_at, expires_at`
+	itemColumns = `id, product_id, variant_id, quantity, created_at, updated_at`
+)
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCart(row rowScanner) (Cart, error) {
+	var c Cart
+	if err := row.Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt, &c.ExpiresAt); err != nil {
+		return Cart{}, err
+	}
+	c.CreatedAt = c.CreatedAt.UTC()
+	c.UpdatedAt = c.UpdatedAt.UTC()
+	c.ExpiresAt = c.ExpiresAt.UTC()
+	return c, nil
+}
+
+func scanItem(row rowScanner) (Item, error) {
+	var item Item
+	var variantID sql.NullString
+	if err := row.Scan(&item.ID, &item.ProductID, &variantID, &item.Quantity, &item.CreatedAt, &item.UpdatedAt); err != nil {
+		return Item{}, err
+	}
+	if variantID.Valid {
+		item.VariantID = &variantID.String
+	}
+	item.CreatedAt = item.CreatedAt.UTC()
+	item.UpdatedAt = item.UpdatedAt.UTC()
+	return item, nil
+}
+
+var (
+	ErrCartNotFound = errors.New("cart not found")
+	ErrItemNotFound = errors.New("cart item not found")
+	ErrTooManyItems = errors.New("cart has too many items")
+)
@@ -0,0 +1,314 @@
+package cart
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"store-serverless/internal/money"
+	"store-serverless/internal/product"
+)
+
+const (
+	defaultCartTTL  = 7 * 24 * time.Hour
+	maxCartItems    = 50
+	maxLineQuantity = 99
+)
+
+type Service struct {
+	repo     *Repository
+	products *product.Repository
+	secret   []byte
+	ttl      time.Duration
+}
+
+func NewService(repo *Repository, products *product.Repository, secret string) *Service {
+	return &Service{
+		repo:     repo,
+		products: products,
+		secret:   []byte(secret),
+		ttl:      defaultCartTTL,
+	}
+}
+
+func (s *Service) WithTTL(ttl time.Duration) {
+	if ttl > 0 {
+		s.ttl = ttl
+	}
+}
+
+func (s *Service) Create(ctx context.Context) (Session, error) {
+	c, err := s.repo.Create(ctx, s.ttl)
+	if err != nil {
+		return Session{}, err
+	}
+	c.Currency = money.DefaultCurrency
+	return Session{Token: s.sign(c.ID), Cart: c}, nil
+}
+
+func (s *Service) Get(ctx context.Context, token string) (Cart, error) {
+	id, err := s.verify(token)
+	if err != nil {
+		return Cart{}, err
+	}
+	return s.load(ctx, id)
+}
+
+func (s *Service) AddItem(ctx context.Context, token string, input ItemInput) (Cart, error) {
+	c, err := s.Get(ctx, token)
+	if err != nil {
+		return Cart{}, err
+	}
+
+	p, err := s.product(ctx, input.ProductID)
+	if err != nil {
+		return Cart{}, err
+	}
+	for _, item := range c.Items {
+		if item.Available && item.Currency != p.Pricing.Currency {
+			return Cart{}, ErrCurrencyMismatch
+		}
+	}
+
+	err = s.repo.AddItem(ctx, c.ID, input.ProductID, input.VariantID, input.Quantity, s.ttl, func(quantity int) error {
+		if quantity > maxLineQuantity {
+			return ErrQuantityTooLarge
+		}
+		return checkLine(p, input.VariantID, quantity)
+	})
+	if err != nil {
+		return Cart{}, err
+	}
+
+	return s.load(ctx, c.ID)
+}
+
+func (s *Service) UpdateItem(ctx context.Context, token, itemID string, quantity int) (Cart, error) {
+	c, err := s.Get(ctx, token)
+	if err != nil {
+		return Cart{}, err
+	}
+
+	item, ok := findItem(c.Items, itemID)
+	if !ok {
+		return Cart{}, ErrItemNotFound
+	}
+
+	if quantity > item.Quantity {
+		p, err := s.product(ctx, item.ProductID)
+		if err != nil {
+			return Cart{}, err
+		}
+		if err := checkLine(p, item.VariantID, quantity); err != nil {
+			return Cart{}, err
+		}
+	}
+
+	if err := s.repo.UpdateItem(ctx, c.ID, itemID, quantity, s.ttl); err != nil {
+		return Cart{}, err
+	}
+
+	return s.load(ctx, c.ID)
+}
+
+func (s *Service) RemoveItem(ctx context.Context, token, itemID string) (Cart, error) {
+	id, err := s.verify(token)
+	if err != nil {
+		return Cart{}, err
+	}
+
+	if err := s.repo.RemoveItem(ctx, id, itemID, s.ttl); err != nil {
+		return Cart{}, err
+	}
+
+	return s.load(ctx, id)
+}
+
+func (s *Service) load(ctx context.Context, id string) (Cart, error) {
+	c, err := s.repo.Get(ctx, id)
+	if err != nil {
+		return Cart{}, err
+	}
+	if err := s.price(ctx, &c); err != nil {
+		return Cart{}, err
+	}
+	return c, nil
+}
+
+func (s *Service) product(ctx context.Context, id string) (product.Product, error) {
+	products, err := s.products.GetPublishedByIDs(ctx, []string{id})
+	if err != nil {
+		return product.Product{}, err
+	}
+	if len(products) == 0 {
+		return product.Product{}, ErrProductUnavailable
+	}
+	return products[0], nil
+}
+
+func (s *Service) price(ctx context.Context, c *Cart) error {
+	if len(c.Items) == 0 {
+		applyProducts(c, nil)
+		return nil
+	}
+
+	products, err := s.products.GetPublishedByIDs(ctx, ProductIDs(*c))
+	if err != nil {
+		return fmt.Errorf("load cart products: %w", err)
+	}
+	applyProducts(c, products)
+	return nil
+}
+
+func ProductIDs(c Cart) []string {
+	ids := make([]string, 0, len(c.Items))
+	for _, item := range c.Items {
+		ids = append(ids, item.ProductID)
+	}
+	return ids
+}
+
+func Reprice(c Cart, products []product.Product) Cart {
+	c.Items = slices.Clone(c.Items)
+	applyProducts(&c, products)
+	return c
+}
+
+func applyProducts(c *Cart, products []product.Product) {
+	c.ItemCount = 0
+	c.Subtotal = 0
+	c.Currency = money.DefaultCurrency
+
+	byID := make(map[string]product.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	currencySet := false
+	for i := range c.Items {
+		item := &c.Items[i]
+		p, ok := byID[item.ProductID]
+		if !ok {
+			continue
+		}
+		applyProduct(item, p)
+		if !item.Available {
+			continue
+		}
+		if !currencySet {
+			c.Currency = item.Currency
+			currencySet = true
+		}
+		if item.Currency != c.Currency {
+			item.Available = false
+			continue
+		}
+		c.ItemCount += item.Quantity
+		c.Subtotal += item.LineTotal
+	}
+}
+
+func applyProduct(item *Item, p product.Product) {
+	item.Title = p.Title
+	item.SKU = p.SKU
+	item.ImageURL = p.ImageURL
+	item.UnitPrice = p.Pricing.EffectivePrice
+	item.Currency = p.Pricing.Currency
+	item.AvailableQuantity = p.AvailableQuantity
+
+	if item.VariantID != nil {
+		v, ok := findVariant(p.Variants, *item.VariantID)
+		if !ok {
+			return
+		}
+		item.SKU = &v.SKU
+		item.Options = v.Options
+		if v.ImageURL != "" {
+			item.ImageURL = v.ImageURL
+		}
+		if v.EffectivePrice != nil {
+			item.UnitPrice = *v.EffectivePrice
+		}
+		item.AvailableQuantity = v.AvailableQuantity
+	}
+
+	item.LineTotal = item.UnitPrice.Mul(item.Quantity)
+	item.Available = checkLine(p, item.VariantID, item.Quantity) == nil
+}
+
+func checkLine(p product.Product, variantID *string, quantity int) error {
+	inStock, available := p.InStock, p.AvailableQuantity
+	if variantID != nil {
+		v, ok := findVariant(p.Variants, *variantID)
+		if !ok {
+			return ErrVariantNotFound
+		}
+		inStock, available = v.InStock, v.AvailableQuantity
+	} else if len(p.Variants) > 0 {
+		return ErrVariantRequired
+	}
+
+	if p.AllowBackorder {
+		return nil
+	}
+	if !inStock || (available != nil && quantity > *available) {
+		return ErrInsufficientStock
+	}
+	return nil
+}
+
+func findVariant(variants []product.Variant, id string) (product.Variant, bool) {
+	for _, v := range variants {
+		if v.ID == id {
+			return v, true
+		}
+	}
+	return product.Variant{}, false
+}
+
+func findItem(items []Item, id string) (Item, bool) {
+	for _, item := range items {
+		if item.ID == id {
+			return item, true
+		}
+	}
+	return Item{}, false
+}
+
+func (s *Service) sign(id string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(id))
+	return id + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Service) verify(token string) (string, error) {
+	id, _, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok {
+		return "", ErrInvalidToken
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return "", ErrInvalidToken
+	}
+	if !hmac.Equal([]byte(s.sign(id)), []byte(strings.TrimSpace(token))) {
+		return "", ErrInvalidToken
+	}
+	return id, nil
+}
+
+var (
+	ErrInvalidToken       = errors.New("invalid cart token")
+	ErrProductUnavailable = errors.New("product is not available")
+	ErrVariantRequired    = errors.New("variant_id is required for this product")
+	ErrVariantNotFound    = errors.New("variant not found")
+	ErrInsufficientStock  = errors.New("insufficient stock")
+	ErrCurrencyMismatch   = errors.New("cart items must share one currency")
+	ErrQuantityTooLarge   = errors.New("quantity is too large")
+)
@@ -0,0 +1,236 @@
+package category
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+)
+
+var slugRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+const (
+	maxJSONBodyBytes     = 1 << 20
+	maxCategoriesPerItem = 20
+)
+
+type Handler struct {
+	repo *Repository
+}
+
+func NewHandler(repo *Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) ListCategories(w http.ResponseWriter, r *http.Request) {
+	tree, err := h.repo.Tree(r.Context())
+	if err != nil {
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to list categories")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"items": tree})
+}
+
+func (h *Handler) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	input, ok := parseInput(w, r)
+	if !ok {
+		return
+	}
+
+	c, err := h.repo.Create(r.Context(), input)
+	if err != nil {
+		if writeRepositoryError(w, err) {
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to create category")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, c)
+}
+
+func (h *Handler) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid category id")
+		return
+	}
+
+	input, ok := parseInput(w, r)
+	if !ok {
+		return
+	}
+
+	c, err := h.repo.Update(r.Context(), id, input)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "category not found")
+			return
+		}
+		if writeRepositoryError(w, err) {
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to update category")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, c)
+}
+
+func (h *Handler) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid category id")
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "category not found")
+			return
+		}
+		if writeRepositoryError(w, err) {
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to delete category")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) SetProductCategories(w http.ResponseWriter, r *http.Request) {
+	productID := r.PathValue("id")
+	if _, err := uuid.Parse(productID); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
+
+	var input ProductCategoriesInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+	if len(input.CategoryIDs) > maxCategoriesPerItem {
+		writeError(w, http.StatusBadRequest, "too many categories")
+		return
+	}
+
+	seen := make(map[string]struct{}, len(input.CategoryIDs))
+	categoryIDs := make([]string, 0, len(input.CategoryIDs))
+	for _, raw := range input.CategoryIDs {
+		parsed, err := uuid.Parse(strings.TrimSpace(raw))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid category id")
+			return
+		}
+		id := parsed.String()
+		if _, dup := seen[id]; dup {
+			continue
+		}
+		seen[id] = struct{}{}
+		categoryIDs = append(categoryIDs, id)
+	}
+
+	categories, err := h.repo.SetProductCategories(r.Context(), productID, categoryIDs)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		if errors.Is(err, ErrCategoryNotFound) {
+			writeError(w, http.StatusBadRequest, "category not found")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to assign categories")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"items": categories})
+}
+
+func parseInput(w http.ResponseWriter, r *http.Request) (CategoryInput, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
+
+	var input CategoryInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return CategoryInput{}, false
+	}
+
+	input.Slug = strings.TrimSpace(input.Slug)
+	input.Name = strings.TrimSpace(input.Name)
+	input.Description = strings.TrimSpace(input.Description)
+
+	if input.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return CategoryInput{}, false
+	}
+	if !utf8.ValidString(input.Name) || len(input.Name) > 100 {
+		writeError(w, http.StatusBadRequest, "name is invalid")
+		return CategoryInput{}, false
+	}
+	if len(input.Slug) > 100 || !slugRegex.MatchString(input.Slug) {
+		writeError(w, http.StatusBadRequest, "slug must contain only lowercase letters, digits and single dashes")
+		return CategoryInput{}, false
+	}
+	if !utf8.ValidString(input.Description) || len(input.Description) > 1000 {
+		writeError(w, http.StatusBadRequest, "description is invalid")
+		return CategoryInput{}, false
+	}
+	if input.ParentID != nil {
+		parsed, err := uuid.Parse(strings.TrimSpace(*input.ParentID))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid parent_id")
+			return CategoryInput{}, false
+		}
+		parentID := parsed.String()
+		input.ParentID = &parentID
+	}
+
+	return input, true
+}
+
+func writeRepositoryError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, ErrSlugTaken):
+		writeError(w, http.StatusConflict, "slug already exists")
+	case errors.Is(err, ErrParentNotFound):
+		writeError(w, http.StatusBadRequest, "parent category not found")
+	case errors.Is(err, ErrCyclicParent):
+		writeError(w, http.StatusBadRequest, "category cannot be nested under itself or its descendants")
+	case errors.Is(err, ErrHasChildren):
+		writeError(w, http.StatusConflict, "category has child categories")
+	default:
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,33 @@
+package category
+
+import "time"
+
+type Category struct {
+	ID          string    `json:"id"`
+	ParentID    *string   `json:"parent_id"`
+	Slug        string    `json:"slug"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Position    int       `json:"position"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type CategoryInput struct {
+	ParentID    *string `json:"parent_id"`
+	Slug        string  `json:"slug"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Position    int     `json:"position"`
+}
+
+type TreeNode struct {
+	Category
+	ProductCount      int         `json:"product_count"`
+	TotalProductCount int         `json:"total_product_count"`
+	Children          []*TreeNode `json:"children"`
+}
+
+type ProductCategoriesInput struct {
+	CategoryIDs []string `json:"category_ids"`
+}
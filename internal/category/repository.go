@@ -0,0 +1,345 @@
+package category
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Tree(ctx context.Context) ([]*TreeNode, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH RECURSIVE subtree AS (
+			SELECT id AS root_id, id
+			FROM categories
+			UNION ALL
+			SELECT subtree.root_id, c.id
+			FROM categories c
+			JOIN subtree ON c.parent_id = subtree.id
+		),
+		assigned AS (
+			SELECT pc.category_id, pc.product_id
+			FROM product_categories pc
+			JOIN products p ON p.id = pc.product_id
+			WHERE p.deleted_at IS NULL AND p.status = 'published' AND (p.publish_at IS NULL OR p.publish_at <= now())
+		)
+		SELECT c.id, c.parent_id, c.slug, c.name, c.description, c.position, c.created_at, c.updated_at,
+			(SELECT COUNT(*) FROM assigned a WHERE a.category_id = c.id),
+			(
+				SELECT COUNT(DISTINCT a.product_id)
+				FROM subtree s
+				JOIN assigned a ON a.category_id = s.id
+				WHERE s.root_id = c.id
+			)
+		FROM categories c
+		ORDER BY c.position ASC, c.name ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query category tree: %w", err)
+	}
+	defer rows.Close()
+
+	nodes := make([]*TreeNode, 0)
+	for rows.Next() {
+		node := &TreeNode{Children: make([]*TreeNode, 0)}
+		c, err := scanCategory(rows, &node.ProductCount, &node.TotalProductCount)
+		if err != nil {
+			return nil, fmt.Errorf("scan category: %w", err)
+		}
+		node.Category = c
+		nodes = append(nodes, node)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate categories: %w", err)
+	}
+
+	return buildTree(nodes), nil
+}
+
+func (r *Repository) Create(ctx context.Context, input CategoryInput) (Category, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return Category{}, fmt.Errorf("generate uuid v7: %w", err)
+	}
+
+	now := time.Now().UTC()
+	c, err := scanCategory(r.db.QueryRowContext(ctx, `
+		INSERT INTO categories (id, parent_id, slug, name, description, position, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		RETURNING `+categoryColumns+`
+	`, id.String(), input.ParentID, input.Slug, input.Name, input.Description, input.Position, now))
+	if err != nil {
+		return Category{}, translateWriteError(err, "insert category")
+	}
+
+	return c, nil
+}
+
+func (r *Repository) Update(ctx context.Context, id string, input CategoryInput) (Category, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Category{}, fmt.Errorf("begin category update tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if input.ParentID != nil {
+		if _, err := tx.ExecContext(ctx, `LOCK TABLE categories IN SHARE ROW EXCLUSIVE MODE`); err != nil {
+			return Category{}, fmt.Errorf("lock categories: %w", err)
+		}
+
+		cyclic, err := isInSubtree(ctx, tx, id, *input.ParentID)
+		if err != nil {
+			return Category{}, err
+		}
+		if cyclic {
+			return Category{}, ErrCyclicParent
+		}
+	}
+
+	now := time.Now().UTC()
+	c, err := scanCategory(tx.QueryRowContext(ctx, `
+		UPDATE categories
+		SET parent_id = $2, slug = $3, name = $4, description = $5, position = $6, updated_at = $7
+		WHERE id = $1
+		RETURNING `+categoryColumns+`
+	`, id, input.ParentID, input.Slug, input.Name, input.Description, input.Position, now))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Category{}, err
+		}
+		return Category{}, translateWriteError(err, "update category")
+	}
+
+	if err := recordCatalogChange(ctx, tx, now); err != nil {
+		return Category{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Category{}, fmt.Errorf("commit category update tx: %w", err)
+	}
+
+	return c, nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin category delete tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM categories WHERE id = $1`, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return ErrHasChildren
+		}
+		return fmt.Errorf("delete category: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := recordCatalogChange(ctx, tx, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit category delete tx: %w", err)
+	}
+
+	return nil
+}
+
+func recordCatalogChange(ctx context.Context, tx *sql.Tx, now time.Time) error {
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO catalog_changes (scope, changed_at)
+		VALUES ('categories', $1)
+		ON CONFLICT (scope) DO UPDATE SET changed_at = GREATEST(catalog_changes.changed_at, EXCLUDED.changed_at)
+	`, now); err != nil {
+		return fmt.Errorf("record catalog change: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) SetProductCategories(ctx context.Context, productID string, categoryIDs []string) ([]Category, error) {
+	if categoryIDs == nil {
+		categoryIDs = []string{}
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin product categories tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var lockedID string
+	if err := tx.QueryRowContext(ctx, `
+		UPDATE products SET updated_at = $2
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING id
+	`, productID, time.Now().UTC()).Scan(&lockedID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("touch product: %w", err)
+	}
+
+	var found int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM categories WHERE id = ANY($1::uuid[])`, categoryIDs).Scan(&found); err != nil {
+		return nil, fmt.Errorf("count categories: %w", err)
+	}
+	if found != len(categoryIDs) {
+		return nil, ErrCategoryNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM product_categories
+		WHERE product_id = $1 AND NOT (category_id = ANY($2::uuid[]))
+	`, productID, categoryIDs); err != nil {
+		return nil, fmt.Errorf("remove product categories: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO product_categories (product_id, category_id)
+		SELECT $1, unnest($2::uuid[])
+		ON CONFLICT (product_id, category_id) DO NOTHING
+	`, productID, categoryIDs); err != nil {
+		return nil, fmt.Errorf("insert product categories: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit product categories tx: %w", err)
+	}
+
+	return r.ListForProduct(ctx, productID)
+}
+
+func (r *Repository) ListForProduct(ctx context.Context, productID string) ([]Category, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+categoryColumns+`
+		FROM categories c
+		JOIN product_categories pc ON pc.category_id = c.id
+		WHERE pc.product_id = $1
+		ORDER BY c.position ASC, c.name ASC
+	`, productID)
+	if err != nil {
+		return nil, fmt.Errorf("query product categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := make([]Category, 0)
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan category: %w", err)
+		}
+		categories = append(categories, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate product categories: %w", err)
+	}
+
+	return categories, nil
+}
+
+func isInSubtree(ctx context.Context, tx *sql.Tx, rootID, candidateID string) (bool, error) {
+	var found bool
+	err := tx.QueryRowContext(ctx, `
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE id = $1
+			UNION ALL
+			SELECT c.id FROM categories c JOIN subtree ON c.parent_id = subtree.id
+		)
+		SELECT EXISTS(SELECT 1 FROM subtree WHERE id = $2)
+	`, rootID, candidateID).Scan(&found)
+	if err != nil {
+		return false, fmt.Errorf("check category subtree: %w", err)
+	}
+
+	return found, nil
+}
+
+const categoryColumns = `id, parent_id, slug, name, description, position, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCategory(row rowScanner, extra ...any) (Category, error) {
+	var c Category
+	var parentID sql.NullString
+	dest := []any{&c.ID, &parentID, &c.Slug, &c.Name, &c.Description, &c.Position, &c.CreatedAt, &c.UpdatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return Category{}, err
+	}
+	if parentID.Valid {
+		c.ParentID = &parentID.String
+	}
+	return c, nil
+}
+
+func buildTree(nodes []*TreeNode) []*TreeNode {
+	byID := make(map[string]*TreeNode, len(nodes))
+	for _, node := range nodes {
+		byID[node.ID] = node
+	}
+
+	roots := make([]*TreeNode, 0)
+	for _, node := range nodes {
+		if node.ParentID != nil {
+			if parent, ok := byID[*node.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	return roots
+}
+
+func translateWriteError(err error, action string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgUniqueViolation:
+			return ErrSlugTaken
+		case pgForeignKeyViolation:
+			return ErrParentNotFound
+		}
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
+
+var (
+	ErrSlugTaken        = errors.New("category slug already exists")
+	ErrParentNotFound   = errors.New("parent category not found")
+	ErrCyclicParent     = errors.New("category cannot be nested under itself")
+	ErrHasChildren      = errors.New("category has child categories")
+	ErrCategoryNotFound = errors.New("category not found")
+)
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_products_created_at;
+
+CREATE INDEX IF NOT EXISTS idx_products_created_at_id ON products(created_at, id);
+
+CREATE INDEX IF NOT EXISTS idx_products_price_id ON products(price, id);
+
+CREATE INDEX IF NOT EXISTS idx_products_title_id ON products(title, id);
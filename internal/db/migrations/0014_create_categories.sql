@@ -0,0 +1,60 @@
+CREATE TABLE IF NOT EXISTS categories (
+    id UUID PRIMARY KEY,
+    parent_id UUID REFERENCES categories(id) ON DELETE RESTRICT,
+    slug TEXT NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT categories_slug_unique UNIQUE (slug),
+    CONSTRAINT categories_slug_format_check CHECK (slug ~ '^[a-z0-9]+(-[a-z0-9]+)*$'),
+    CONSTRAINT categories_parent_not_self_check CHECK (parent_id IS NULL OR parent_id <> id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories(parent_id);
+
+CREATE TABLE IF NOT EXISTS product_categories (
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (product_id, category_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_categories_category_id ON product_categories(category_id);
+
+INSERT INTO categories (id, parent_id, slug, name, position)
+VALUES
+    ('0194ff0a-0f11-7000-8000-ec4a7d0c2001', NULL, 'cuidado-de-la-piel', 'Cuidado de la piel', 1),
+    ('0194ff0a-0f11-7000-8000-ec4a7d0c2002', NULL, 'fragancias', 'Fragancias', 2),
+    ('0194ff0a-0f11-7000-8000-ec4a7d0c2003', NULL, 'maquillaje', 'Maquillaje', 3),
+    ('0194ff0a-0f11-7000-8000-ec4a7d0c2004', '0194ff0a-0f11-7000-8000-ec4a7d0c2003', 'rostro', 'Rostro', 1),
+    ('0194ff0a-0f11-7000-8000-ec4a7d0c2005', '0194ff0a-0f11-7000-8000-ec4a7d0c2003', 'ojos', 'Ojos', 2),
+    ('0194ff0a-0f11-7000-8000-ec4a7d0c2006', '0194ff0a-0f11-7000-8000-ec4a7d0c2003', 'labios', 'Labios', 3),
+    ('0194ff0a-0f11-7000-8000-ec4a7d0c2007', NULL, 'accesorios-para-el-cabello', 'Accesorios para el cabello', 4)
+ON CONFLICT (id) DO NOTHING;
+
+INSERT INTO product_categories (product_id, category_id)
+SELECT seed.product_id, seed.category_id
+FROM (
+    VALUES
+        ('0194ff0a-0f11-7000-8000-ec4a7d0b1001'::uuid, '0194ff0a-0f11-7000-8000-ec4a7d0c2001'::uuid),
+        ('0194ff0a-0f11-7000-8000-ec4a7d0b1002'::uuid, '0194ff0a-0f11-7000-8000-ec4a7d0c2002'::uuid),
+        ('0194ff0a-0f11-7000-8000-ec4a7d0b1003'::uuid, '0194ff0a-0f11-7000-8000-ec4a7d0c2004'::uuid),
+        ('0194ff0a-0f11-7000-8000-ec4a7d0b1004'::uuid, '0194ff0a-0f11-7000-8000-ec4a7d0c2004'::uuid),
+        ('0194ff0a-0f11-7000-8000-ec4a7d0b1005'::uuid, '0194ff0a-0f11-7000-8000-ec4a7d0c2004'::uuid),
+        ('0194ff0a-0f11-7000-8000-ec4a7d0b1006'::uuid, '0194ff0a-0f11-7000-8000-ec4a7d0c2004'::uuid),
+        ('0194ff0a-0f11-7000-8000-ec4a7d0b1007'::uuid, '0194ff0a-0f11-7000-8000-ec4a7d0c2004'::uuid),
+        ('0194ff0a-0f11-7000-8000-ec4a7d0b1008'::uuid, '0194ff0a-0f11-7000-8000-ec4a7d0c2004'::uuid),
+        ('0194ff0a-0f11-7000-8000-ec4a7d0b1009'::uuid, '0194ff0a-0f11-7000-8000-ec4a7d0c2004'::uuid),
+        ('0194ff0a-0f11-7000-8000-ec4a7d0b100a'::uuid, '0194ff0a-0f11-7000-8000-ec4a7d0c2004'::uuid),
+        ('0194ff0a-0f11-7000-8000-ec4a7d0b100b'::uuid, '0194ff0a-0f11-7000-8000-ec4a7d0c2005'::uuid),
+        ('0194ff0a-0f11-7000-8000-ec4a7d0b100c'::uuid, '0194ff0a-0f11-7000-8000-ec4a7d0c2005'::uuid),
+        ('0194ff0a-0f11-7000-8000-ec4a7d0b100d'::uuid, '0194ff0a-0f11-7000-8000-ec4a7d0c2005'::uuid),
+        ('0194ff0a-0f11-7000-8000-ec4a7d0b100e'::uuid, '0194ff0a-0f11-7000-8000-ec4a7d0c2006'::uuid),
+        ('0194ff0a-0f11-7000-8000-ec4a7d0b100f'::uuid, '0194ff0a-0f11-7000-8000-ec4a7d0c2006'::uuid),
+        ('0194ff0a-0f11-7000-8000-ec4a7d0b1010'::uuid, '0194ff0a-0f11-7000-8000-ec4a7d0c2007'::uuid),
+        ('0194ff0a-0f11-7000-8000-ec4a7d0b1011'::uuid, '0194ff0a-0f11-7000-8000-ec4a7d0c2007'::uuid)
+) AS seed(product_id, category_id)
+JOIN products p ON p.id = seed.product_id
+ON CONFLICT (product_id, category_id) DO NOTHING;
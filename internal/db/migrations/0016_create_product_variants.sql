@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS product_variants (
+    id UUID PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    sku TEXT NOT NULL,
+    options JSONB NOT NULL DEFAULT '{}'::jsonb,
+    price DOUBLE PRECISION,
+    image_url TEXT NOT NULL DEFAULT '',
+    stock_quantity INTEGER,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT product_variants_sku_unique UNIQUE (sku),
+    CONSTRAINT product_variants_options_unique UNIQUE (product_id, options),
+    CONSTRAINT product_variants_options_object_check CHECK (jsonb_typeof(options) = 'object'),
+    CONSTRAINT product_variants_price_check CHECK (price IS NULL OR price >= 0),
+    CONSTRAINT product_variants_image_url_check CHECK (
+        image_url = ''
+        OR (
+            image_url ~ '^[A-Za-z0-9\-._~:/?#\[\]@!$&''()*+,;=%]+$'
+            AND image_url ~* '^https?://'
+        )
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_variants_product_id ON product_variants(product_id, position, id);
+
+ALTER TABLE stock_movements
+ADD COLUMN IF NOT EXISTS variant_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_stock_movements_variant_id ON stock_movements(variant_id, id DESC)
+WHERE variant_id IS NOT NULL;
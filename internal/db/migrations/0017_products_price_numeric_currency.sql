@@ -0,0 +1,12 @@
+ALTER TABLE products
+ALTER COLUMN price TYPE NUMERIC(12, 2) USING round(price::numeric, 2);
+
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS currency TEXT NOT NULL DEFAULT 'PEN';
+
+ALTER TABLE products
+ADD CONSTRAINT products_currency_check
+CHECK (currency ~ '^[A-Z]{3}$');
+
+ALTER TABLE product_variants
+ALTER COLUMN price TYPE NUMERIC(12, 2) USING round(price::numeric, 2);
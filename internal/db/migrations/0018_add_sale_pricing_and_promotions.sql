@@ -0,0 +1,27 @@
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS sale_price NUMERIC(12, 2),
+ADD COLUMN IF NOT EXISTS sale_starts_at TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS sale_ends_at TIMESTAMPTZ;
+
+ALTER TABLE products
+ADD CONSTRAINT products_sale_price_check
+CHECK (sale_price IS NULL OR (sale_price >= 0 AND sale_price < price));
+
+ALTER TABLE products
+ADD CONSTRAINT products_sale_window_check
+CHECK (sale_starts_at IS NULL OR sale_ends_at IS NULL OR sale_ends_at > sale_starts_at);
+
+CREATE TABLE IF NOT EXISTS promotions (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    percent_off INTEGER NOT NULL,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT promotions_percent_off_check CHECK (percent_off BETWEEN 1 AND 90),
+    CONSTRAINT promotions_window_check CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotions_category_id ON promotions(category_id);
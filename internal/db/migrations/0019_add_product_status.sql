@@ -0,0 +1,12 @@
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'published',
+ADD COLUMN IF NOT EXISTS publish_at TIMESTAMPTZ;
+
+ALTER TABLE products
+ALTER COLUMN status SET DEFAULT 'draft';
+
+ALTER TABLE products
+ADD CONSTRAINT products_status_check
+CHECK (status IN ('draft', 'published', 'archived'));
+
+CREATE INDEX IF NOT EXISTS idx_products_status_publish_at ON products(status, publish_at);
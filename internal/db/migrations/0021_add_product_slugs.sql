@@ -0,0 +1,46 @@
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS slug TEXT;
+
+WITH base AS (
+    SELECT
+        id,
+        created_at,
+        COALESCE(
+            NULLIF(
+                trim(BOTH '-' FROM left(
+                    trim(BOTH '-' FROM lower(regexp_replace(unaccent(title), '[^A-Za-z0-9]+', '-', 'g'))),
+                    80
+                )),
+                ''
+            ),
+            'producto'
+        ) AS slug
+    FROM products
+    WHERE slug IS NULL
+),
+ranked AS (
+    SELECT id, slug, row_number() OVER (PARTITION BY slug ORDER BY created_at, id) AS n
+    FROM base
+)
+UPDATE products p
+SET slug = CASE WHEN ranked.n = 1 THEN ranked.slug ELSE ranked.slug || '-' || ranked.n END
+FROM ranked
+WHERE p.id = ranked.id;
+
+ALTER TABLE products
+ALTER COLUMN slug SET NOT NULL;
+
+ALTER TABLE products
+ADD CONSTRAINT products_slug_unique UNIQUE (slug);
+
+ALTER TABLE products
+ADD CONSTRAINT products_slug_format_check
+CHECK (slug ~ '^[a-z0-9]+(-[a-z0-9]+)*$');
+
+CREATE TABLE IF NOT EXISTS product_slug_history (
+    slug TEXT PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_slug_history_product_id ON product_slug_history(product_id);
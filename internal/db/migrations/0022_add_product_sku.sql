@@ -0,0 +1,9 @@
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS sku TEXT;
+
+ALTER TABLE products
+ADD CONSTRAINT products_sku_unique UNIQUE (sku);
+
+ALTER TABLE products
+ADD CONSTRAINT products_sku_format_check
+CHECK (sku IS NULL OR sku ~ '^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$');
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS attribute_definitions (
+    key TEXT PRIMARY KEY,
+    label TEXT NOT NULL,
+    type TEXT NOT NULL,
+    allowed_values JSONB NOT NULL DEFAULT '[]'::jsonb,
+    unit TEXT NOT NULL DEFAULT '',
+    filterable BOOLEAN NOT NULL DEFAULT TRUE,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT attribute_definitions_key_format_check CHECK (key ~ '^[a-z][a-z0-9_]{0,39}$'),
+    CONSTRAINT attribute_definitions_type_check CHECK (type IN ('text', 'number', 'boolean', 'enum'))
+);
+
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX IF NOT EXISTS idx_products_attributes
+ON products USING GIN (attributes);
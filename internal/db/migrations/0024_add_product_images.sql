@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS product_images (
+    id UUID PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    alt_text TEXT NOT NULL DEFAULT '',
+    width INTEGER,
+    height INTEGER,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL,
+    CONSTRAINT product_images_width_check CHECK (width IS NULL OR width > 0),
+    CONSTRAINT product_images_height_check CHECK (height IS NULL OR height > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_images_product_position
+ON product_images (product_id, position, id);
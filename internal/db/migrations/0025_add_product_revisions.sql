@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS product_revisions (
+    id UUID PRIMARY KEY,
+    product_id UUID NOT NULL,
+    action TEXT NOT NULL,
+    actor TEXT,
+    changes JSONB NOT NULL DEFAULT '{}'::jsonb,
+    snapshot JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT product_revisions_action_check CHECK (action IN ('create', 'update', 'delete', 'restore', 'rollback'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_revisions_product_id ON product_revisions(product_id, id DESC);
+
+CREATE OR REPLACE FUNCTION product_revisions_append_only()
+RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'product_revisions is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS product_revisions_append_only ON product_revisions;
+
+CREATE TRIGGER product_revisions_append_only
+BEFORE UPDATE OR DELETE ON product_revisions
+FOR EACH ROW EXECUTE FUNCTION product_revisions_append_only();
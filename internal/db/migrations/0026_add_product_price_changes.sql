@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS product_price_changes (
+    id UUID PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    old_price NUMERIC(12,2) NOT NULL,
+    new_price NUMERIC(12,2) NOT NULL,
+    currency TEXT NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_price_changes_product_id ON product_price_changes(product_id, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_product_price_changes_product_changed_at ON product_price_changes(product_id, changed_at);
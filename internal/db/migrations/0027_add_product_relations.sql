@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS product_relations (
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    related_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (product_id, related_id, kind),
+    CONSTRAINT product_relations_kind_check CHECK (kind IN ('related', 'accessory', 'upsell')),
+    CONSTRAINT product_relations_self_check CHECK (product_id <> related_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_relations_product_position ON product_relations(product_id, position);
+
+CREATE INDEX IF NOT EXISTS idx_product_relations_related_id ON product_relations(related_id);
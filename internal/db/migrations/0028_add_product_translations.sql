@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS product_translations (
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    locale TEXT NOT NULL,
+    title TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (product_id, locale),
+    CONSTRAINT product_translations_locale_check CHECK (locale IN ('en'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_translations_locale ON product_translations(locale, product_id);
@@ -0,0 +1,21 @@
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS rating_average NUMERIC(3,2) NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS rating_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS product_reviews (
+    id UUID PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    rating SMALLINT NOT NULL,
+    title TEXT NOT NULL DEFAULT '',
+    body TEXT NOT NULL,
+    author_name TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    moderated_at TIMESTAMPTZ,
+    CONSTRAINT product_reviews_rating_check CHECK (rating BETWEEN 1 AND 5),
+    CONSTRAINT product_reviews_status_check CHECK (status IN ('pending', 'approved', 'rejected'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_reviews_product_status ON product_reviews(product_id, status, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_product_reviews_status ON product_reviews(status, id);
@@ -0,0 +1,120 @@
+CREATE TABLE IF NOT EXISTS orders (
+    id UUID PRIMARY KEY,
+    status TEXT NOT NULL DEFAULT 'pending',
+    currency CHAR(3) NOT NULL,
+    total NUMERIC(12,2) NOT NULL,
+    item_count INTEGER NOT NULL,
+    customer_name TEXT NOT NULL,
+    customer_email TEXT NOT NULL,
+    customer_phone TEXT NOT NULL DEFAULT '',
+    shipping_address TEXT NOT NULL,
+    notes TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    paid_at TIMESTAMPTZ,
+    shipped_at TIMESTAMPTZ,
+    delivered_at TIMESTAMPTZ,
+    cancelled_at TIMESTAMPTZ,
+    refunded_at TIMESTAMPTZ,
+    CONSTRAINT orders_status_check CHECK (status IN ('pending', 'paid', 'shipped', 'delivered', 'cancelled', 'refunded')),
+    CONSTRAINT orders_total_check CHECK (total >= 0),
+    CONSTRAINT orders_item_count_check CHECK (item_count > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_status ON orders(status, id DESC);
+
+CREATE INDEX IF NOT EXISTS idx_orders_customer_email ON orders(lower(customer_email), id DESC);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    product_id UUID REFERENCES products(id) ON DELETE SET NULL,
+    variant_id UUID REFERENCES product_variants(id) ON DELETE SET NULL,
+    sku TEXT,
+    title TEXT NOT NULL,
+    options JSONB NOT NULL DEFAULT '{}'::jsonb,
+    unit_price NUMERIC(12,2) NOT NULL,
+    quantity INTEGER NOT NULL,
+    line_total NUMERIC(12,2) NOT NULL,
+    position INTEGER NOT NULL,
+    CONSTRAINT order_items_quantity_check CHECK (quantity > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_order_id ON order_items(order_id, position);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_product_id ON order_items(product_id);
+
+CREATE TABLE IF NOT EXISTS order_status_changes (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    from_status TEXT,
+    to_status TEXT NOT NULL,
+    actor TEXT,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_changes_order_id ON order_status_changes(order_id, id);
+
+CREATE OR REPLACE FUNCTION orders_snapshot_immutable()
+RETURNS trigger AS $$
+BEGIN
+    IF NEW.currency IS DISTINCT FROM OLD.currency
+        OR NEW.total IS DISTINCT FROM OLD.total
+        OR NEW.item_count IS DISTINCT FROM OLD.item_count
+        OR NEW.customer_name IS DISTINCT FROM OLD.customer_name
+        OR NEW.customer_email IS DISTINCT FROM OLD.customer_email
+        OR NEW.customer_phone IS DISTINCT FROM OLD.customer_phone
+        OR NEW.shipping_address IS DISTINCT FROM OLD.shipping_address
+        OR NEW.notes IS DISTINCT FROM OLD.notes
+        OR NEW.created_at IS DISTINCT FROM OLD.created_at THEN
+        RAISE EXCEPTION 'orders snapshot is immutable';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS orders_snapshot_immutable ON orders;
+
+CREATE TRIGGER orders_snapshot_immutable
+BEFORE UPDATE ON orders
+FOR EACH ROW EXECUTE FUNCTION orders_snapshot_immutable();
+
+CREATE OR REPLACE FUNCTION order_items_immutable()
+RETURNS trigger AS $$
+BEGIN
+    IF NEW.id IS DISTINCT FROM OLD.id
+        OR NEW.order_id IS DISTINCT FROM OLD.order_id
+        OR (NEW.product_id IS NOT NULL AND NEW.product_id IS DISTINCT FROM OLD.product_id)
+        OR (NEW.variant_id IS NOT NULL AND NEW.variant_id IS DISTINCT FROM OLD.variant_id)
+        OR NEW.sku IS DISTINCT FROM OLD.sku
+        OR NEW.title IS DISTINCT FROM OLD.title
+        OR NEW.options IS DISTINCT FROM OLD.options
+        OR NEW.unit_price IS DISTINCT FROM OLD.unit_price
+        OR NEW.quantity IS DISTINCT FROM OLD.quantity
+        OR NEW.line_total IS DISTINCT FROM OLD.line_total
+        OR NEW.position IS DISTINCT FROM OLD.position THEN
+        RAISE EXCEPTION 'order_items is immutable';
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS order_items_immutable ON order_items;
+
+CREATE TRIGGER order_items_immutable
+BEFORE UPDATE ON order_items
+FOR EACH ROW EXECUTE FUNCTION order_items_immutable();
+
+CREATE OR REPLACE FUNCTION order_status_changes_append_only()
+RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'order_status_changes is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS order_status_changes_append_only ON order_status_changes;
+
+CREATE TRIGGER order_status_changes_append_only
+BEFORE UPDATE OR DELETE ON order_status_changes
+FOR EACH ROW EXECUTE FUNCTION order_status_changes_append_only();
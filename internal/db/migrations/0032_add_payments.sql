@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS payments (
+    id UUID PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(id),
+    provider TEXT NOT NULL,
+    intent_id TEXT NOT NULL,
+    client_secret TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    amount NUMERIC(12,2) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT payments_status_check CHECK (status IN ('requires_payment', 'succeeded', 'failed', 'refunded')),
+    CONSTRAINT payments_provider_intent_key UNIQUE (provider, intent_id)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_payments_active_order
+ON payments(order_id)
+WHERE status IN ('requires_payment', 'succeeded');
+
+CREATE INDEX IF NOT EXISTS idx_payments_order_id ON payments(order_id, id DESC);
+
+CREATE TABLE IF NOT EXISTS payment_events (
+    provider TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    type TEXT NOT NULL,
+    intent_id TEXT NOT NULL,
+    result TEXT NOT NULL,
+    received_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (provider, event_id)
+);
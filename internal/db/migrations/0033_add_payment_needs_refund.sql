@@ -0,0 +1,5 @@
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_status_check;
+
+ALTER TABLE payments
+ADD CONSTRAINT payments_status_check
+CHECK (status IN ('requires_payment', 'succeeded', 'failed', 'needs_refund', 'refunded'));
@@ -0,0 +1,7 @@
+ALTER TABLE product_reviews
+ADD COLUMN IF NOT EXISTS order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+ADD COLUMN IF NOT EXISTS verified BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_product_reviews_order_product
+ON product_reviews(order_id, product_id)
+WHERE order_id IS NOT NULL;
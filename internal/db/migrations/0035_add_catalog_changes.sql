@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS catalog_changes (
+    scope TEXT PRIMARY KEY,
+    changed_at TIMESTAMPTZ NOT NULL
+);
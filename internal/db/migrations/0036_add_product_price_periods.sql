@@ -0,0 +1,44 @@
+ALTER TABLE product_price_changes ADD COLUMN IF NOT EXISTS old_currency TEXT;
+
+UPDATE product_price_changes SET old_currency = currency WHERE old_currency IS NULL;
+
+ALTER TABLE product_price_changes ALTER COLUMN old_currency SET NOT NULL;
+
+CREATE TABLE IF NOT EXISTS product_price_periods (
+    id UUID PRIMARY KEY,
+    product_id UUID NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    price NUMERIC(12,2) NOT NULL,
+    currency TEXT NOT NULL,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ,
+    CONSTRAINT product_price_periods_kind_check CHECK (kind IN ('price', 'sale')),
+    CONSTRAINT product_price_periods_range_check CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_price_periods_product_id ON product_price_periods(product_id, ends_at);
+
+INSERT INTO product_price_periods (id, product_id, kind, price, currency, starts_at, ends_at)
+SELECT gen_random_uuid(), h.product_id, 'price', h.old_price, h.old_currency, h.starts_at, h.changed_at
+FROM (
+    SELECT c.product_id, c.old_price, c.old_currency, c.changed_at,
+        COALESCE(LAG(c.changed_at) OVER (PARTITION BY c.product_id ORDER BY c.changed_at), p.created_at) AS starts_at
+    FROM product_price_changes c
+    JOIN products p ON p.id = c.product_id
+) h
+WHERE h.changed_at > h.starts_at
+  AND NOT EXISTS (SELECT 1 FROM product_price_periods pp WHERE pp.product_id = h.product_id);
+
+INSERT INTO product_price_periods (id, product_id, kind, price, currency, starts_at, ends_at)
+SELECT gen_random_uuid(), p.id, 'price', p.price, p.currency,
+    COALESCE((SELECT MAX(c.changed_at) FROM product_price_changes c WHERE c.product_id = p.id), p.created_at), NULL
+FROM products p
+WHERE NOT EXISTS (SELECT 1 FROM product_price_periods pp WHERE pp.product_id = p.id AND pp.ends_at IS NULL);
+
+INSERT INTO product_price_periods (id, product_id, kind, price, currency, starts_at, ends_at)
+SELECT gen_random_uuid(), p.id, 'sale', p.sale_price, p.currency, s.starts_at, p.sale_ends_at
+FROM products p
+CROSS JOIN LATERAL (SELECT GREATEST(COALESCE(p.sale_starts_at, p.updated_at), p.created_at) AS starts_at) s
+WHERE p.sale_price IS NOT NULL
+  AND (p.sale_ends_at IS NULL OR p.sale_ends_at > s.starts_at)
+  AND NOT EXISTS (SELECT 1 FROM product_price_periods pp WHERE pp.product_id = p.id AND pp.kind = 'sale');
@@ -0,0 +1,2 @@
+ALTER TABLE products
+ADD COLUMN IF NOT EXISTS rating_updated_at TIMESTAMPTZ;
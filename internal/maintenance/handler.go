@@ -7,7 +7,9 @@ import (
 	"time"
 
 	"store-serverless/internal/auth"
+	"store-serverless/internal/cart"
 	"store-serverless/internal/observability"
+	"store-serverless/internal/product"
 )
 
 type CleanupHandler struct {
@@ -17,6 +19,9 @@ type CleanupHandler struct {
 	refreshRetention      time.Duration
 	loginAttemptRetention time.Duration
 	batchSize             int
+	products              *product.Repository
+	trashRetention        time.Duration
+	carts                 *cart.Repository
 }
 
 func NewCleanupHandler(
@@ -37,6 +42,15 @@ func NewCleanupHandler(
 	}
 }
 
+func (h *CleanupHandler) WithProductTrash(products *product.Repository, retention time.Duration) {
+	h.products = products
+	h.trashRetention = retention
+}
+
+func (h *CleanupHandler) WithCarts(carts *cart.Repository) {
+	h.carts = carts
+}
+
 func (h *CleanupHandler) Handle(w http.ResponseWriter, r *http.Request) {
 	if h.cronSecret == "" {
 		writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
@@ -67,10 +81,36 @@ func (h *CleanupHandler) Handle(w http.ResponseWriter, r *http.Request) {
 		"deleted_login_attempts": result.DeletedLoginAttempts,
 	})
 
-	writeJSON(w, http.StatusOK, map[string]any{
+	response := map[string]any{
 		"status": "ok",
 		"result": result,
-	})
+	}
+
+	if h.products != nil {
+		purged, err := h.products.PurgeTrash(r.Context(), h.trashRetention, h.batchSize)
+		if err != nil {
+			h.logger.Error("product_trash_purge_failed", map[string]any{"error": err.Error()})
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "cleanup failed"})
+			return
+		}
+
+		h.logger.Info("product_trash_purge_completed", map[string]any{"purged_products": purged})
+		response["purged_products"] = purged
+	}
+
+	if h.carts != nil {
+		deleted, err := h.carts.DeleteExpired(r.Context(), h.batchSize)
+		if err != nil {
+			h.logger.Error("cart_cleanup_failed", map[string]any{"error": err.Error()})
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "cleanup failed"})
+			return
+		}
+
+		h.logger.Info("cart_cleanup_completed", map[string]any{"deleted_carts": deleted})
+		response["deleted_carts"] = deleted
+	}
+
+	writeJSON(w, http.StatusOK, response)
 }
 
 func writeJSON(w http.ResponseWriter, status int, data any) {
@@ -0,0 +1,153 @@
+package money
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const DefaultCurrency = "PEN"
+
+const maxAmount = Amount(999_999_999_999)
+
+var amountRegex = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+var currencyRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+
+var (
+	ErrInvalidAmount   = errors.New("invalid amount")
+	ErrTooManyDecimals = errors.New("amount has more than 2 decimals")
+	ErrOutOfRange      = errors.New("amount is out of range")
+)
+
+type Amount int64
+
+func FromMinor(minor int64) Amount {
+	return Amount(minor)
+}
+
+func Parse(value string) (Amount, error) {
+	value = strings.TrimSpace(value)
+	if !amountRegex.MatchString(value) {
+		return 0, ErrInvalidAmount
+	}
+
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+
+	whole, fraction, _ := strings.Cut(value, ".")
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > 2 {
+		return 0, ErrTooManyDecimals
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+
+	whole = strings.TrimLeft(whole, "0")
+	if len(whole) > 10 {
+		return 0, ErrOutOfRange
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	minor, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidAmount
+	}
+
+	amount := Amount(minor)
+	if amount > maxAmount {
+		return 0, ErrOutOfRange
+	}
+	if negative {
+		amount = -amount
+	}
+
+	return amount, nil
+}
+
+func ValidCurrency(code string) bool {
+	return currencyRegex.MatchString(code)
+}
+
+func (a Amount) Minor() int64 {
+	return int64(a)
+}
+
+func (a Amount) String() string {
+	sign := ""
+	minor := int64(a)
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, minor/100, minor%100)
+}
+
+func (a Amount) Mul(quantity int) Amount {
+	return a * Amount(quantity)
+}
+
+func (a Amount) ApplyPercentOff(percent int) Amount {
+	discount := math.Round(float64(a) * float64(percent) / 100)
+	return a - Amount(discount)
+}
+
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.String())
+}
+
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	raw := strings.TrimSpace(string(data))
+	if raw == "null" {
+		return nil
+	}
+
+	if strings.HasPrefix(raw, `"`) {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return ErrInvalidAmount
+		}
+		raw = text
+	}
+
+	parsed, err := Parse(raw)
+	if err != nil {
+		return err
+	}
+	*a = parsed
+	return nil
+}
+
+func (a *Amount) Scan(src any) error {
+	switch value := src.(type) {
+	case string:
+		return a.scanText(value)
+	case []byte:
+		return a.scanText(string(value))
+	case int64:
+		*a = Amount(value * 100)
+		return nil
+	case float64:
+		return a.scanText(strconv.FormatFloat(value, 'f', -1, 64))
+	default:
+		return fmt.Errorf("cannot scan %T into money.Amount", src)
+	}
+}
+
+func (a *Amount) scanText(value string) error {
+	parsed, err := Parse(value)
+	if err != nil {
+		return fmt.Errorf("scan amount %q: %w", value, err)
+	}
+	*a = parsed
+	return nil
+}
+
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
@@ -0,0 +1,186 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Amount
+		wantErr error
+	}{
+		{"whole number", "12", 1200, nil},
+		{"one decimal", "12.5", 1250, nil},
+		{"two decimals", "12.34", 1234, nil},
+		{"trailing zeros beyond cents", "12.3400", 1234, nil},
+		{"leading zeros", "007.01", 701, nil},
+		{"surrounding spaces", "  3.10 ", 310, nil},
+		{"zero", "0", 0, nil},
+		{"negative", "-4.05", -405, nil},
+		{"negative zero", "-0.00", 0, nil},
+		{"largest NUMERIC(12,2)", "9999999999.99", 999_999_999_999, nil},
+		{"largest negative NUMERIC(12,2)", "-9999999999.99", -999_999_999_999, nil},
+		{"eleven whole digits", "10000000000", 0, ErrOutOfRange},
+		{"eleven whole digits with leading zeros", "00010000000000.00", 0, ErrOutOfRange},
+		{"too many decimals", "1.234", 0, ErrTooManyDecimals},
+		{"too many decimals on a negative", "-1.001", 0, ErrTooManyDecimals},
+		{"exponent", "1e3", 0, ErrInvalidAmount},
+		{"float with exponent", "1.5E2", 0, ErrInvalidAmount},
+		{"missing whole part", ".50", 0, ErrInvalidAmount},
+		{"missing fraction", "5.", 0, ErrInvalidAmount},
+		{"comma separator", "5,00", 0, ErrInvalidAmount},
+		{"plus sign", "+5", 0, ErrInvalidAmount},
+		{"empty", "", 0, ErrInvalidAmount},
+		{"currency symbol", "S/ 5.00", 0, ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("Parse(%q) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		amount Amount
+		want   string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{50, "0.50"},
+		{1234, "12.34"},
+		{-5, "-0.05"},
+		{-1234, "-12.34"},
+		{999_999_999_999, "9999999999.99"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.amount.String(); got != tt.want {
+			t.Fatalf("Amount(%d).String() = %q, want %q", int64(tt.amount), got, tt.want)
+		}
+	}
+}
+
+func TestApplyPercentOff(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  Amount
+		percent int
+		want    Amount
+	}{
+		{"no discount", 1999, 0, 1999},
+		{"full discount", 1999, 100, 0},
+		{"exact cents", 10000, 15, 8500},
+		{"half cent discount rounds up", 105, 10, 94},
+		{"below half cent discount rounds down", 104, 10, 94},
+		{"above half cent discount rounds up", 106, 10, 95},
+		{"one third", 1000, 33, 670},
+		{"largest amount", 999_999_999_999, 50, 499_999_999_999},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.amount.ApplyPercentOff(tt.percent); got != tt.want {
+				t.Fatalf("Amount(%d).ApplyPercentOff(%d) = %d, want %d", int64(tt.amount), tt.percent, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Amount
+		wantErr error
+	}{
+		{"string", `"12.34"`, 1234, nil},
+		{"number", `12.34`, 1234, nil},
+		{"integer number", `12`, 1200, nil},
+		{"negative string", `"-1.50"`, -150, nil},
+		{"null keeps the value", `null`, 777, nil},
+		{"number with too many decimals", `12.345`, 777, ErrTooManyDecimals},
+		{"string with too many decimals", `"0.001"`, 777, ErrTooManyDecimals},
+		{"number with exponent", `1e2`, 777, ErrInvalidAmount},
+		{"string with exponent", `"1e2"`, 777, ErrInvalidAmount},
+		{"out of range", `"10000000000.00"`, 777, ErrOutOfRange},
+		{"boolean", `true`, 777, ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Amount(777)
+			err := json.Unmarshal([]byte(tt.input), &got)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Unmarshal(%s) error = %v, want %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("Unmarshal(%s) = %d, want %d", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	for _, amount := range []Amount{0, 1, 99, 1234, -1234, 999_999_999_999} {
+		data, err := json.Marshal(amount)
+		if err != nil {
+			t.Fatalf("Marshal(%d): %v", int64(amount), err)
+		}
+		var got Amount
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", data, err)
+		}
+		if got != amount {
+			t.Fatalf("round trip of %d = %d (via %s)", int64(amount), got, data)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     any
+		want    Amount
+		wantErr error
+	}{
+		{"numeric text", "12.30", 1230, nil},
+		{"numeric bytes", []byte("0.99"), 99, nil},
+		{"integer", int64(12), 1200, nil},
+		{"float", float64(12.5), 1250, nil},
+		{"negative text", "-3.00", -300, nil},
+		{"largest NUMERIC(12,2)", "9999999999.99", 999_999_999_999, nil},
+		{"inexact float", float64(0.1) + float64(0.2), 0, ErrTooManyDecimals},
+		{"float with exponent", float64(1e21), 0, ErrOutOfRange},
+		{"garbage", "abc", 0, ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Amount
+			err := got.Scan(tt.src)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Scan(%v) error = %v, want %v", tt.src, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Fatalf("Scan(%v) = %d, want %d", tt.src, got, tt.want)
+			}
+		})
+	}
+
+	var got Amount
+	if err := got.Scan(true); err == nil {
+		t.Fatal("Scan(true) error = nil, want an error")
+	}
+}
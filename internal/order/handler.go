@@ -0,0 +1,275 @@
+package order
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+
+	"store-serverless/internal/auth"
+	"store-serverless/internal/cart"
+)
+
+const (
+	maxJSONBodyBytes = 16 << 10
+	cartTokenHeader  = "X-Cart-Token"
+	orderTokenHeader = "X-Order-Token"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) Checkout(w http.ResponseWriter, r *http.Request) {
+	var input CheckoutInput
+	if !decodeBody(w, r, &input) {
+		return
+	}
+	if !checkCheckoutInput(w, &input) {
+		return
+	}
+
+	receipt, err := h.service.Checkout(r.Context(), r.Header.Get(cartTokenHeader), input)
+	if err != nil {
+		switch {
+		case errors.Is(err, cart.ErrInvalidToken):
+			writeError(w, http.StatusUnauthorized, "missing or invalid cart token")
+		case errors.Is(err, cart.ErrCartNotFound):
+			writeError(w, http.StatusNotFound, "cart not found or expired")
+		case errors.Is(err, ErrEmptyCart), errors.Is(err, ErrUnavailableItems), errors.Is(err, ErrInsufficientStock):
+			writeError(w, http.StatusConflict, err.Error())
+		case errors.Is(err, ErrCartChanged):
+			writeError(w, http.StatusConflict, "cart changed during checkout, review it and try again")
+		default:
+			sentry.CaptureException(err)
+			writeError(w, http.StatusInternalServerError, "failed to place order")
+		}
+		return
+	}
+
+	w.Header().Set(orderTokenHeader, receipt.Token)
+	writeJSON(w, http.StatusCreated, receipt)
+}
+
+func (h *Handler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseOrderID(w, r)
+	if !ok {
+		return
+	}
+
+	if _, admin := auth.Subject(r.Context()); !admin && !h.service.Authorize(id, r.Header.Get(orderTokenHeader)) {
+		writeError(w, http.StatusNotFound, "order not found")
+		return
+	}
+
+	o, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "order not found")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to get order")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, o)
+}
+
+func (h *Handler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	params, ok := parseListParams(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.service.List(r.Context(), params)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to list orders")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (h *Handler) TransitionOrder(w http.ResponseWriter, r *http.Request) {
+	id, ok := parseOrderID(w, r)
+	if !ok {
+		return
+	}
+
+	var input TransitionInput
+	if !decodeBody(w, r, &input) {
+		return
+	}
+	input.Status = strings.ToLower(strings.TrimSpace(input.Status))
+	input.Note = strings.TrimSpace(input.Note)
+	if !slices.Contains(statuses, input.Status) {
+		writeError(w, http.StatusBadRequest, "status must be one of "+strings.Join(statuses, ", "))
+		return
+	}
+	if !utf8.ValidString(input.Note) || utf8.RuneCountInString(input.Note) > 500 {
+		writeError(w, http.StatusBadRequest, "note must be at most 500 characters")
+		return
+	}
+
+	var actor *string
+	if subject, ok := auth.Subject(r.Context()); ok {
+		actor = &subject
+	}
+
+	o, err := h.service.Transition(r.Context(), id, input.Status, input.Note, actor)
+	if err != nil {
+		var transitionErr ErrInvalidTransition
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			writeError(w, http.StatusNotFound, "order not found")
+		case errors.As(err, &transitionErr):
+			writeError(w, http.StatusConflict, transitionErr.Error())
+		default:
+			sentry.CaptureException(err)
+			writeError(w, http.StatusInternalServerError, "failed to update order status")
+		}
+		return
+	}
+
+	writeJSON(w, http.StatusOK, o)
+}
+
+func checkCheckoutInput(w http.ResponseWriter, input *CheckoutInput) bool {
+	customer := &input.Customer
+	customer.Name = strings.TrimSpace(customer.Name)
+	customer.Email = strings.TrimSpace(customer.Email)
+	customer.Phone = strings.TrimSpace(customer.Phone)
+	customer.ShippingAddress = strings.TrimSpace(customer.ShippingAddress)
+	input.Notes = strings.TrimSpace(input.Notes)
+
+	if customer.Name == "" || !utf8.ValidString(customer.Name) || utf8.RuneCountInString(customer.Name) > 100 {
+		writeError(w, http.StatusBadRequest, "customer.name is required and must be at most 100 characters")
+		return false
+	}
+	if address, err := mail.ParseAddress(customer.Email); err != nil || address.Address != customer.Email || len(customer.Email) > 254 {
+		writeError(w, http.StatusBadRequest, "customer.email must be a valid email address")
+		return false
+	}
+	if !utf8.ValidString(customer.Phone) || utf8.RuneCountInString(customer.Phone) > 30 {
+		writeError(w, http.StatusBadRequest, "customer.phone must be at most 30 characters")
+		return false
+	}
+	if customer.ShippingAddress == "" || !utf8.ValidString(customer.ShippingAddress) || utf8.RuneCountInString(customer.ShippingAddress) > 500 {
+		writeError(w, http.StatusBadRequest, "customer.shipping_address is required and must be at most 500 characters")
+		return false
+	}
+	if !utf8.ValidString(input.Notes) || utf8.RuneCountInString(input.Notes) > 1000 {
+		writeError(w, http.StatusBadRequest, "notes must be at most 1000 characters")
+		return false
+	}
+
+	return true
+}
+
+func parseListParams(w http.ResponseWriter, r *http.Request) (ListParams, bool) {
+	query := r.URL.Query()
+	params := ListParams{Limit: defaultListLimit, Cursor: strings.TrimSpace(query.Get("cursor"))}
+
+	if raw := strings.TrimSpace(query.Get("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxListLimit {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxListLimit))
+			return ListParams{}, false
+		}
+		params.Limit = limit
+	}
+
+	params.Status = strings.ToLower(strings.TrimSpace(query.Get("status")))
+	if params.Status != "" && !slices.Contains(statuses, params.Status) {
+		writeError(w, http.StatusBadRequest, "status must be one of "+strings.Join(statuses, ", "))
+		return ListParams{}, false
+	}
+
+	params.Email = strings.TrimSpace(query.Get("email"))
+	if len(params.Email) > 254 {
+		writeError(w, http.StatusBadRequest, "email is invalid")
+		return ListParams{}, false
+	}
+
+	if raw := strings.TrimSpace(query.Get("product_id")); raw != "" {
+		if _, err := uuid.Parse(raw); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid product_id")
+			return ListParams{}, false
+		}
+		params.ProductID = raw
+	}
+
+	var ok bool
+	if params.CreatedAfter, ok = parseTimeParam(w, query.Get("created_after"), "created_after"); !ok {
+		return ListParams{}, false
+	}
+	if params.CreatedBefore, ok = parseTimeParam(w, query.Get("created_before"), "created_before"); !ok {
+		return ListParams{}, false
+	}
+
+	return params, true
+}
+
+func parseTimeParam(w http.ResponseWriter, raw, name string) (*time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, true
+	}
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, name+" must be an RFC3339 timestamp")
+		return nil, false
+	}
+	return &value, true
+}
+
+func parseOrderID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid order id")
+		return "", false
+	}
+	return id, true
+}
+
+func decodeBody(w http.ResponseWriter, r *http.Request, target any) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return false
+	}
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
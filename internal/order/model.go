@@ -0,0 +1,111 @@
+package order
+
+import (
+	"slices"
+	"time"
+
+	"store-serverless/internal/money"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusPaid      = "paid"
+	StatusShipped   = "shipped"
+	StatusDelivered = "delivered"
+	StatusCancelled = "cancelled"
+	StatusRefunded  = "refunded"
+)
+
+var statuses = []string{StatusPending, StatusPaid, StatusShipped, StatusDelivered, StatusCancelled, StatusRefunded}
+
+var transitions = map[string][]string{
+	StatusPending:   {StatusPaid, StatusCancelled},
+	StatusPaid:      {StatusShipped, StatusRefunded},
+	StatusShipped:   {StatusDelivered, StatusRefunded},
+	StatusDelivered: {StatusRefunded},
+}
+
+func CanTransition(from, to string) bool {
+	return slices.Contains(transitions[from], to)
+}
+
+func restocks(from, to string) bool {
+	return (to == StatusCancelled || to == StatusRefunded) && (from == StatusPending || from == StatusPaid)
+}
+
+type Order struct {
+	ID          string         `json:"id"`
+	Status      string         `json:"status"`
+	Currency    string         `json:"currency"`
+	Total       money.Amount   `json:"total"`
+	ItemCount   int            `json:"item_count"`
+	Customer    Customer       `json:"customer"`
+	Notes       string         `json:"notes"`
+	Items       []Item         `json:"items"`
+	History     []StatusChange `json:"history,omitempty"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	PaidAt      *time.Time     `json:"paid_at"`
+	ShippedAt   *time.Time     `json:"shipped_at"`
+	DeliveredAt *time.Time     `json:"delivered_at"`
+	CancelledAt *time.Time     `json:"cancelled_at"`
+	RefundedAt  *time.Time     `json:"refunded_at"`
+}
+
+type Customer struct {
+	Name            string `json:"name"`
+	Email           string `json:"email"`
+	Phone           string `json:"phone"`
+	ShippingAddress string `json:"shipping_address"`
+}
+
+type Item struct {
+	ID        string            `json:"id"`
+	ProductID *string           `json:"product_id"`
+	VariantID *string           `json:"variant_id"`
+	SKU       *string           `json:"sku"`
+	Title     string            `json:"title"`
+	Options   map[string]string `json:"options,omitempty"`
+	UnitPrice money.Amount      `json:"unit_price"`
+	Quantity  int               `json:"quantity"`
+	LineTotal money.Amount      `json:"line_total"`
+}
+
+type StatusChange struct {
+	ID        string    `json:"id"`
+	From      *string   `json:"from"`
+	To        string    `json:"to"`
+	Actor     *string   `json:"actor"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type CheckoutInput struct {
+	Customer Customer `json:"customer"`
+	Notes    string   `json:"notes"`
+}
+
+type TransitionInput struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+type Receipt struct {
+	Token string `json:"token"`
+	Order Order  `json:"order"`
+}
+
+type ListParams struct {
+	Status        string
+	Email         string
+	ProductID     string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Limit         int
+	Cursor        string
+}
+
+type Page struct {
+	Items      []Order `json:"items"`
+	NextCursor *string `json:"next_cursor"`
+}
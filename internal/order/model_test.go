@@ -0,0 +1,51 @@
+package order
+
+import "testing"
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		allowed  bool
+		restocks bool
+	}{
+		{"pending order is paid", StatusPending, StatusPaid, true, false},
+		{"pending order is cancelled", StatusPending, StatusCancelled, true, true},
+		{"paid order is shipped", StatusPaid, StatusShipped, true, false},
+		{"paid order is refunded", StatusPaid, StatusRefunded, true, true},
+		{"shipped order is delivered", StatusShipped, StatusDelivered, true, false},
+		{"shipped order is refunded", StatusShipped, StatusRefunded, true, false},
+		{"delivered order is refunded", StatusDelivered, StatusRefunded, true, false},
+		{"pending order cannot ship", StatusPending, StatusShipped, false, false},
+		{"pending order cannot be refunded", StatusPending, StatusRefunded, false, true},
+		{"paid order cannot go back to pending", StatusPaid, StatusPending, false, false},
+		{"paid order cannot be cancelled", StatusPaid, StatusCancelled, false, true},
+		{"shipped order cannot be cancelled", StatusShipped, StatusCancelled, false, false},
+		{"delivered order cannot go back to paid", StatusDelivered, StatusPaid, false, false},
+		{"delivered order cannot be cancelled", StatusDelivered, StatusCancelled, false, false},
+		{"cancelled order cannot be paid", StatusCancelled, StatusPaid, false, false},
+		{"cancelled order cannot be reopened", StatusCancelled, StatusPending, false, false},
+		{"cancelled order cannot ship", StatusCancelled, StatusShipped, false, false},
+		{"cancelled order cannot be delivered", StatusCancelled, StatusDelivered, false, false},
+		{"cancelled order cannot be refunded", StatusCancelled, StatusRefunded, false, false},
+		{"refunded order cannot be paid", StatusRefunded, StatusPaid, false, false},
+		{"refunded order cannot be reopened", StatusRefunded, StatusPending, false, false},
+		{"refunded order cannot ship", StatusRefunded, StatusShipped, false, false},
+		{"refunded order cannot be delivered", StatusRefunded, StatusDelivered, false, false},
+		{"refunded order cannot be cancelled", StatusRefunded, StatusCancelled, false, false},
+		{"same status", StatusPaid, StatusPaid, false, false},
+		{"unknown status", "archived", StatusPaid, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanTransition(tt.from, tt.to); got != tt.allowed {
+				t.Fatalf("CanTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.allowed)
+			}
+			if got := restocks(tt.from, tt.to); got != tt.restocks {
+				t.Fatalf("restocks(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.restocks)
+			}
+		})
+	}
+}
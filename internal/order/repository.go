@@ -0,0 +1,546 @@
+package order
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"store-serverless/internal/cart"
+	"store-serverless/internal/product"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+var statusTimestampColumns = map[string]string{
+	StatusPaid:      "paid_at",
+	StatusShipped:   "shipped_at",
+	StatusDelivered: "delivered_at",
+	StatusCancelled: "cancelled_at",
+	StatusRefunded:  "refunded_at",
+}
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) Create(ctx context.Context, c cart.Cart, input CheckoutInput) (Order, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return Order{}, fmt.Errorf("generate uuid v7: %w", err)
+	}
+	orderID := id.String()
+	now := time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Order{}, fmt.Errorf("begin create order tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `
+		DELETE FROM carts
+		WHERE id = $1 AND updated_at = $2 AND expires_at > $3
+	`, c.ID, c.UpdatedAt, now)
+	if err != nil {
+		return Order{}, fmt.Errorf("consume cart: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return Order{}, fmt.Errorf("consume cart rows affected: %w", err)
+	}
+	if affected == 0 {
+		return Order{}, ErrCartChanged
+	}
+
+	productIDs := cart.ProductIDs(c)
+	products, err := product.LockPublished(ctx, tx, productIDs)
+	if err != nil {
+		return Order{}, err
+	}
+	if len(products) != len(slices.Compact(slices.Sorted(slices.Values(productIDs)))) || !samePricing(c, cart.Reprice(c, products)) {
+		return Order{}, ErrCartChanged
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO orders (
+			id, status, currency, total, item_count,
+			customer_name, customer_email, customer_phone, shipping_address, notes,
+			created_at, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $11)
+	`, orderID, StatusPending, c.Currency, c.Subtotal, c.ItemCount,
+		input.Customer.Name, input.Customer.Email, input.Customer.Phone, input.Customer.ShippingAddress, input.Notes,
+		now); err != nil {
+		return Order{}, fmt.Errorf("insert order: %w", err)
+	}
+
+	for i, item := range c.Items {
+		itemID, err := uuid.NewV7()
+		if err != nil {
+			return Order{}, fmt.Errorf("generate uuid v7: %w", err)
+		}
+		options, err := json.Marshal(item.Options)
+		if err != nil {
+			return Order{}, fmt.Errorf("encode order item options: %w", err)
+		}
+		if item.Options == nil {
+			options = []byte("{}")
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO order_items (id, order_id, product_id, variant_id, sku, title, options, unit_price, quantity, line_total, position)
+			VALUES ($1, $2, $3, $4, $5, $6, $7::jsonb, $8, $9, $10, $11)
+		`, itemID.String(), orderID, item.ProductID, item.VariantID, item.SKU, item.Title, string(options),
+			item.UnitPrice, item.Quantity, item.LineTotal, i); err != nil {
+			return Order{}, fmt.Errorf("insert order item: %w", err)
+		}
+
+		if err := product.MoveStock(ctx, tx, item.ProductID, item.VariantID, -item.Quantity, "sale", "order "+orderID); err != nil {
+			if errors.Is(err, product.ErrInsufficientStock) {
+				return Order{}, ErrInsufficientStock
+			}
+			if errors.Is(err, sql.ErrNoRows) {
+				return Order{}, ErrCartChanged
+			}
+			return Order{}, err
+		}
+	}
+
+	if err := insertStatusChange(ctx, tx, orderID, nil, StatusPending, nil, "", now); err != nil {
+		return Order{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Order{}, fmt.Errorf("commit create order tx: %w", err)
+	}
+
+	return r.Get(ctx, orderID)
+}
+
+func samePricing(priced, current cart.Cart) bool {
+	if priced.Currency != current.Currency {
+		return false
+	}
+	for i, item := range priced.Items {
+		fresh := current.Items[i]
+		if fresh.Title != item.Title || fresh.UnitPrice != item.UnitPrice || fresh.Currency != item.Currency || !equalSKU(fresh.SKU, item.SKU) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalSKU(a, b *string) bool {
+	return (a == nil) == (b == nil) && (a == nil || *a == *b)
+}
+
+func (r *Repository) Get(ctx context.Context, id string) (Order, error) {
+	o, err := scanOrder(r.db.QueryRowContext(ctx, `
+		SELECT `+orderColumns+`
+		FROM orders
+		WHERE id = $1
+	`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Order{}, err
+		}
+		return Order{}, fmt.Errorf("get order: %w", err)
+	}
+
+	orders := []Order{o}
+	if err := r.loadItems(ctx, orders); err != nil {
+		return Order{}, err
+	}
+	o = orders[0]
+
+	o.History, err = r.loadHistory(ctx, id)
+	if err != nil {
+		return Order{}, err
+	}
+
+	return o, nil
+}
+
+func (r *Repository) HasPurchased(ctx context.Context, orderID, productID string) (bool, error) {
+	var purchased bool
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM orders o
+			JOIN order_items oi ON oi.order_id = o.id
+			WHERE o.id = $1 AND oi.product_id = $2 AND o.status IN ('paid', 'shipped', 'delivered')
+		)
+	`, orderID, productID).Scan(&purchased); err != nil {
+		return false, fmt.Errorf("check order purchase: %w", err)
+	}
+	return purchased, nil
+}
+
+func (r *Repository) List(ctx context.Context, params ListParams) (Page, error) {
+	limit := params.Limit
+	if limit <= 0 || limit > maxListLimit {
+		limit = defaultListLimit
+	}
+
+	args := make([]any, 0, 7)
+	arg := func(value any) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	conditions := make([]string, 0, 6)
+	if params.Status != "" {
+		conditions = append(conditions, "o.status = "+arg(params.Status))
+	}
+	if params.Email != "" {
+		conditions = append(conditions, "lower(o.customer_email) = lower("+arg(params.Email)+")")
+	}
+	if params.ProductID != "" {
+		conditions = append(conditions, "EXISTS (SELECT 1 FROM order_items oi WHERE oi.order_id = o.id AND oi.product_id = "+arg(params.ProductID)+")")
+	}
+	if params.CreatedAfter != nil {
+		conditions = append(conditions, "o.created_at >= "+arg(*params.CreatedAfter))
+	}
+	if params.CreatedBefore != nil {
+		conditions = append(conditions, "o.created_at <= "+arg(*params.CreatedBefore))
+	}
+	if params.Cursor != "" {
+		id, err := decodeCursor(params.Cursor)
+		if err != nil {
+			return Page{}, err
+		}
+		conditions = append(conditions, "o.id < "+arg(id)+"::uuid")
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+prefixedOrderColumns+`
+		FROM orders o
+		`+where+`
+		ORDER BY o.id DESC
+		LIMIT `+arg(limit+1), args...)
+	if err != nil {
+		return Page{}, fmt.Errorf("query orders: %w", err)
+	}
+	defer rows.Close()
+
+	orders := make([]Order, 0, limit)
+	for rows.Next() {
+		o, err := scanOrder(rows)
+		if err != nil {
+			return Page{}, fmt.Errorf("scan order: %w", err)
+		}
+		orders = append(orders, o)
+	}
+
+	if err := rows.Err(); err != nil {
+		return Page{}, fmt.Errorf("iterate orders: %w", err)
+	}
+
+	page := Page{Items: orders}
+	if len(orders) > limit {
+		page.Items = orders[:limit]
+		next := encodeCursor(page.Items[limit-1].ID)
+		page.NextCursor = &next
+	}
+
+	if err := r.loadItems(ctx, page.Items); err != nil {
+		return Page{}, err
+	}
+
+	return page, nil
+}
+
+func (r *Repository) Transition(ctx context.Context, id, status, note string, actor *string) (Order, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Order{}, fmt.Errorf("begin order transition tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := TransitionTx(ctx, tx, id, status, note, actor); err != nil {
+		return Order{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Order{}, fmt.Errorf("commit order transition tx: %w", err)
+	}
+
+	return r.Get(ctx, id)
+}
+
+func TransitionTx(ctx context.Context, tx *sql.Tx, id, status, note string, actor *string) error {
+	now := time.Now().UTC()
+
+	var current string
+	if err := tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, id).Scan(&current); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		return fmt.Errorf("lock order: %w", err)
+	}
+	if !CanTransition(current, status) {
+		return ErrInvalidTransition{From: current, To: status}
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE orders
+		SET status = $2, updated_at = $3, `+statusTimestampColumns[status]+` = $3
+		WHERE id = $1
+	`, id, status, now); err != nil {
+		return fmt.Errorf("update order status: %w", err)
+	}
+
+	if err := insertStatusChange(ctx, tx, id, &current, status, actor, note, now); err != nil {
+		return err
+	}
+
+	if restocks(current, status) {
+		return restock(ctx, tx, id, status)
+	}
+
+	return nil
+}
+
+func restock(ctx context.Context, tx *sql.Tx, orderID, status string) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT product_id, variant_id, quantity
+		FROM order_items
+		WHERE order_id = $1 AND product_id IS NOT NULL
+		ORDER BY position ASC
+	`, orderID)
+	if err != nil {
+		return fmt.Errorf("query order items to restock: %w", err)
+	}
+
+	items := make([]Item, 0)
+	for rows.Next() {
+		var item Item
+		var productID string
+		var variantID sql.NullString
+		if err := rows.Scan(&productID, &variantID, &item.Quantity); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan order item to restock: %w", err)
+		}
+		item.ProductID = &productID
+		if variantID.Valid {
+			item.VariantID = &variantID.String
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return fmt.Errorf("iterate order items to restock: %w", err)
+	}
+	rows.Close()
+
+	for _, item := range items {
+		err := product.MoveStock(ctx, tx, *item.ProductID, item.VariantID, item.Quantity, "return", "order "+orderID+" "+status)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func insertStatusChange(ctx context.Context, tx *sql.Tx, orderID string, from *string, to string, actor *string, note string, now time.Time) error {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("generate uuid v7: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO order_status_changes (id, order_id, from_status, to_status, actor, note, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, id.String(), orderID, from, to, actor, note, now); err != nil {
+		return fmt.Errorf("insert order status change: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) loadItems(ctx context.Context, orders []Order) error {
+	if len(orders) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(orders))
+	index := make(map[string]int, len(orders))
+	for i := range orders {
+		orders[i].Items = make([]Item, 0)
+		ids = append(ids, orders[i].ID)
+		index[orders[i].ID] = i
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT order_id, id, product_id, variant_id, sku, title, options, unit_price, quantity, line_total
+		FROM order_items
+		WHERE order_id = ANY($1::uuid[])
+		ORDER BY order_id, position ASC
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("query order items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID string
+		var item Item
+		var productID, variantID, sku sql.NullString
+		var options []byte
+		if err := rows.Scan(&orderID, &item.ID, &productID, &variantID, &sku, &item.Title, &options, &item.UnitPrice, &item.Quantity, &item.LineTotal); err != nil {
+			return fmt.Errorf("scan order item: %w", err)
+		}
+		if productID.Valid {
+			item.ProductID = &productID.String
+		}
+		if variantID.Valid {
+			item.VariantID = &variantID.String
+		}
+		if sku.Valid {
+			item.SKU = &sku.String
+		}
+		if err := json.Unmarshal(options, &item.Options); err != nil {
+			return fmt.Errorf("decode order item options: %w", err)
+		}
+		if len(item.Options) == 0 {
+			item.Options = nil
+		}
+
+		i := index[orderID]
+		orders[i].Items = append(orders[i].Items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate order items: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) loadHistory(ctx context.Context, orderID string) ([]StatusChange, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, from_status, to_status, actor, note, created_at
+		FROM order_status_changes
+		WHERE order_id = $1
+		ORDER BY id ASC
+	`, orderID)
+	if err != nil {
+		return nil, fmt.Errorf("query order status changes: %w", err)
+	}
+	defer rows.Close()
+
+	history := make([]StatusChange, 0)
+	for rows.Next() {
+		var change StatusChange
+		var from, actor sql.NullString
+		if err := rows.Scan(&change.ID, &from, &change.To, &actor, &change.Note, &change.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan order status change: %w", err)
+		}
+		if from.Valid {
+			change.From = &from.String
+		}
+		if actor.Valid {
+			change.Actor = &actor.String
+		}
+		change.CreatedAt = change.CreatedAt.UTC()
+		history = append(history, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate order status changes: %w", err)
+	}
+
+	return history, nil
+}
+
+const orderColumns = `id, status, currency, total, item_count,
+	customer_name, customer_email, customer_phone, shipping_address, notes,
+	created_at, updated_at, paid_at, shipped_at, delivered_at, cancelled_at, refunded_at`
+
+const prefixedOrderColumns = `o.id, o.status, o.currency, o.total, o.item_count,
+	o.customer_name, o.customer_email, o.customer_phone, o.shipping_address, o.notes,
+	o.created_at, o.updated_at, o.paid_at, o.shipped_at, o.delivered_at, o.cancelled_at, o.refunded_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanOrder(row rowScanner) (Order, error) {
+	var o Order
+	var paidAt, shippedAt, deliveredAt, cancelledAt, refundedAt sql.NullTime
+	if err := row.Scan(
+		&o.ID, &o.Status, &o.Currency, &o.Total, &o.ItemCount,
+		&o.Customer.Name, &o.Customer.Email, &o.Customer.Phone, &o.Customer.ShippingAddress, &o.Notes,
+		&o.CreatedAt, &o.UpdatedAt, &paidAt, &shippedAt, &deliveredAt, &cancelledAt, &refundedAt,
+	); err != nil {
+		return Order{}, err
+	}
+	o.CreatedAt = o.CreatedAt.UTC()
+	o.UpdatedAt = o.UpdatedAt.UTC()
+	o.PaidAt = nullTime(paidAt)
+	o.ShippedAt = nullTime(shippedAt)
+	o.DeliveredAt = nullTime(deliveredAt)
+	o.CancelledAt = nullTime(cancelledAt)
+	o.RefundedAt = nullTime(refundedAt)
+	o.Items = make([]Item, 0)
+	return o, nil
+}
+
+func nullTime(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	t := value.Time.UTC()
+	return &t
+}
+
+func encodeCursor(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+func decodeCursor(raw string) (string, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return "", ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(string(decoded)); err != nil {
+		return "", ErrInvalidCursor
+	}
+	return string(decoded), nil
+}
+
+type ErrInvalidTransition struct {
+	From string
+	To   string
+}
+
+func (e ErrInvalidTransition) Error() string {
+	return fmt.Sprintf("cannot move order from %s to %s", e.From, e.To)
+}
+
+var (
+	ErrCartChanged       = errors.New("cart changed during checkout")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	ErrInvalidCursor     = errors.New("invalid cursor")
+)
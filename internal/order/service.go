@@ -0,0 +1,78 @@
+package order
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"store-serverless/internal/cart"
+)
+
+type Service struct {
+	repo   *Repository
+	carts  *cart.Service
+	secret []byte
+}
+
+func NewService(repo *Repository, carts *cart.Service, secret string) *Service {
+	return &Service{repo: repo, carts: carts, secret: []byte(secret)}
+}
+
+func (s *Service) Checkout(ctx context.Context, cartToken string, input CheckoutInput) (Receipt, error) {
+	c, err := s.carts.Get(ctx, cartToken)
+	if err != nil {
+		return Receipt{}, err
+	}
+	if len(c.Items) == 0 {
+		return Receipt{}, ErrEmptyCart
+	}
+	for _, item := range c.Items {
+		if !item.Available {
+			return Receipt{}, ErrUnavailableItems
+		}
+	}
+
+	o, err := s.repo.Create(ctx, c, input)
+	if err != nil {
+		return Receipt{}, err
+	}
+
+	return Receipt{Token: s.sign(o.ID), Order: o}, nil
+}
+
+func (s *Service) Get(ctx context.Context, id string) (Order, error) {
+	return s.repo.Get(ctx, id)
+}
+
+func (s *Service) List(ctx context.Context, params ListParams) (Page, error) {
+	return s.repo.List(ctx, params)
+}
+
+func (s *Service) Transition(ctx context.Context, id, status, note string, actor *string) (Order, error) {
+	return s.repo.Transition(ctx, id, status, note, actor)
+}
+
+func (s *Service) Authorize(id, token string) bool {
+	return hmac.Equal([]byte(s.sign(id)), []byte(strings.TrimSpace(token)))
+}
+
+func (s *Service) VerifyPurchase(ctx context.Context, orderID, token, productID string) (bool, error) {
+	if !s.Authorize(orderID, token) {
+		return false, nil
+	}
+	return s.repo.HasPurchased(ctx, orderID, productID)
+}
+
+func (s *Service) sign(id string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("order:" + id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+var (
+	ErrEmptyCart        = errors.New("cart is empty")
+	ErrUnavailableItems = errors.New("cart has unavailable items")
+)
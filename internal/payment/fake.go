@@ -0,0 +1,89 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"store-serverless/internal/money"
+)
+
+const (
+	FakeProviderName    = "fake"
+	fakeSignatureHeader = "X-Fake-Signature"
+	fakeIntentPrefix    = "fake_pi_"
+)
+
+type Fake struct {
+	secret []byte
+}
+
+type fakeEvent struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	IntentID string `json:"intent_id"`
+}
+
+func NewFake(secret string) *Fake {
+	return &Fake{secret: []byte(secret)}
+}
+
+func (f *Fake) Name() string {
+	return FakeProviderName
+}
+
+func (f *Fake) CreateIntent(ctx context.Context, request IntentRequest) (Intent, error) {
+	id := fakeIntentPrefix + f.digest("intent:" + request.Reference)[:24]
+	return Intent{
+		ID:           id,
+		Status:       StatusRequiresPayment,
+		ClientSecret: id + "_secret_" + f.digest("secret:" + id)[:16],
+		Amount:       request.Amount,
+		Currency:     request.Currency,
+	}, nil
+}
+
+func (f *Fake) Capture(ctx context.Context, intentID string, amount money.Amount) (Intent, error) {
+	if !strings.HasPrefix(intentID, fakeIntentPrefix) {
+		return Intent{}, errors.New("fake provider: unknown intent")
+	}
+	return Intent{ID: intentID, Status: StatusSucceeded, Amount: amount}, nil
+}
+
+func (f *Fake) Refund(ctx context.Context, intentID string, amount money.Amount) (Intent, error) {
+	if !strings.HasPrefix(intentID, fakeIntentPrefix) {
+		return Intent{}, errors.New("fake provider: unknown intent")
+	}
+	return Intent{ID: intentID, Status: StatusRefunded, Amount: amount}, nil
+}
+
+func (f *Fake) VerifyWebhook(payload []byte, header http.Header) (Event, error) {
+	signature := strings.TrimSpace(header.Get(fakeSignatureHeader))
+	if !hmac.Equal([]byte(signature), []byte(f.Sign(payload))) {
+		return Event{}, ErrInvalidSignature
+	}
+
+	var event fakeEvent
+	if err := json.Unmarshal(payload, &event); err != nil || event.ID == "" || event.IntentID == "" {
+		return Event{}, ErrInvalidEvent
+	}
+
+	return Event{ID: event.ID, Type: event.Type, IntentID: event.IntentID}, nil
+}
+
+func (f *Fake) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, f.secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (f *Fake) digest(value string) string {
+	mac := hmac.New(sha256.New, f.secret)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,70 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"store-serverless/internal/money"
+)
+
+func TestFakeCreateIntentIsDeterministic(t *testing.T) {
+	fake := NewFake("secret")
+	request := IntentRequest{Reference: "ref-1", OrderID: "order-1", Amount: money.FromMinor(1500), Currency: "PEN"}
+
+	first, err := fake.CreateIntent(context.Background(), request)
+	if err != nil {
+		t.Fatalf("create intent: %v", err)
+	}
+	second, err := fake.CreateIntent(context.Background(), request)
+	if err != nil {
+		t.Fatalf("create intent: %v", err)
+	}
+	if first != second {
+		t.Fatalf("intents differ for the same reference: %+v vs %+v", first, second)
+	}
+	if !strings.HasPrefix(first.ID, fakeIntentPrefix) || first.Status != StatusRequiresPayment {
+		t.Fatalf("unexpected intent: %+v", first)
+	}
+
+	request.Reference = "ref-2"
+	other, err := fake.CreateIntent(context.Background(), request)
+	if err != nil {
+		t.Fatalf("create intent: %v", err)
+	}
+	if other.ID == first.ID {
+		t.Fatalf("different references share intent id %s", other.ID)
+	}
+}
+
+func TestFakeVerifyWebhook(t *testing.T) {
+	fake := NewFake("secret")
+	payload := []byte(`{"id":"evt_1","type":"payment.succeeded","intent_id":"fake_pi_1"}`)
+
+	header := http.Header{}
+	header.Set(fakeSignatureHeader, fake.Sign(payload))
+	event, err := fake.VerifyWebhook(payload, header)
+	if err != nil {
+		t.Fatalf("verify webhook: %v", err)
+	}
+	if event != (Event{ID: "evt_1", Type: EventPaymentSucceeded, IntentID: "fake_pi_1"}) {
+		t.Fatalf("unexpected event: %+v", event)
+	}
+
+	tampered := []byte(strings.Replace(string(payload), "evt_1", "evt_2", 1))
+	if _, err := fake.VerifyWebhook(tampered, header); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("tampered payload: got %v, want ErrInvalidSignature", err)
+	}
+
+	if _, err := NewFake("other").VerifyWebhook(payload, header); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("wrong secret: got %v, want ErrInvalidSignature", err)
+	}
+
+	invalid := []byte(`{"type":"payment.succeeded"}`)
+	header.Set(fakeSignatureHeader, fake.Sign(invalid))
+	if _, err := fake.VerifyWebhook(invalid, header); !errors.Is(err, ErrInvalidEvent) {
+		t.Fatalf("invalid payload: got %v, want ErrInvalidEvent", err)
+	}
+}
@@ -0,0 +1,145 @@
+package payment
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+
+	"store-serverless/internal/auth"
+	"store-serverless/internal/order"
+)
+
+const (
+	maxWebhookBodyBytes = 64 << 10
+	orderTokenHeader    = "X-Order-Token"
+)
+
+type Handler struct {
+	service *Service
+}
+
+func NewHandler(service *Service) *Handler {
+	return &Handler{service: service}
+}
+
+func (h *Handler) CreatePayment(w http.ResponseWriter, r *http.Request) {
+	orderID, ok := parseOrderID(w, r)
+	if !ok {
+		return
+	}
+
+	if _, admin := auth.Subject(r.Context()); !admin && !h.service.Authorize(orderID, r.Header.Get(orderTokenHeader)) {
+		writeError(w, http.StatusNotFound, "order not found")
+		return
+	}
+
+	p, created, err := h.service.CreateIntent(r.Context(), orderID)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to create payment")
+		return
+	}
+
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	writeJSON(w, status, p)
+}
+
+func (h *Handler) CapturePayment(w http.ResponseWriter, r *http.Request) {
+	orderID, ok := parseOrderID(w, r)
+	if !ok {
+		return
+	}
+
+	subject, _ := auth.Subject(r.Context())
+	p, err := h.service.Capture(r.Context(), orderID, subject)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to capture payment")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (h *Handler) RefundPayment(w http.ResponseWriter, r *http.Request) {
+	orderID, ok := parseOrderID(w, r)
+	if !ok {
+		return
+	}
+
+	subject, _ := auth.Subject(r.Context())
+	p, err := h.service.Refund(r.Context(), orderID, subject)
+	if err != nil {
+		h.writeServiceError(w, err, "failed to refund payment")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (h *Handler) Webhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid webhook body")
+		return
+	}
+
+	result, err := h.service.HandleWebhook(r.Context(), payload, r.Header)
+	if err != nil {
+		if errors.Is(err, ErrInvalidSignature) {
+			writeError(w, http.StatusUnauthorized, "invalid webhook signature")
+			return
+		}
+		if errors.Is(err, ErrInvalidEvent) {
+			writeError(w, http.StatusBadRequest, "invalid webhook event")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to process webhook")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) writeServiceError(w http.ResponseWriter, err error, message string) {
+	var transitionErr order.ErrInvalidTransition
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "order not found")
+	case errors.Is(err, ErrPaymentNotFound):
+		writeError(w, http.StatusNotFound, "payment not found")
+	case errors.Is(err, ErrOrderNotPayable), errors.Is(err, ErrPaymentInProgress):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.As(err, &transitionErr):
+		writeError(w, http.StatusConflict, transitionErr.Error())
+	default:
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, message)
+	}
+}
+
+func parseOrderID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid order id")
+		return "", false
+	}
+	return id, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,40 @@
+package payment
+
+import (
+	"time"
+
+	"store-serverless/internal/money"
+)
+
+const (
+	ResultProcessed      = "processed"
+	ResultOrderUnchanged = "order_unchanged"
+	ResultNeedsRefund    = "needs_refund"
+	ResultIgnored        = "ignored"
+	ResultDuplicate      = "duplicate"
+)
+
+var statusTransitions = map[string][]string{
+	StatusRequiresPayment: {StatusSucceeded, StatusFailed},
+	StatusFailed:          {StatusSucceeded},
+	StatusSucceeded:       {StatusRefunded},
+	StatusNeedsRefund:     {StatusRefunded},
+}
+
+type Payment struct {
+	ID           string       `json:"id"`
+	OrderID      string       `json:"order_id"`
+	Provider     string       `json:"provider"`
+	IntentID     string       `json:"intent_id"`
+	ClientSecret string       `json:"client_secret,omitempty"`
+	Status       string       `json:"status"`
+	Amount       money.Amount `json:"amount"`
+	Currency     string       `json:"currency"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+type WebhookResult struct {
+	Status  string   `json:"status"`
+	Payment *Payment `json:"payment,omitempty"`
+}
@@ -0,0 +1,59 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"store-serverless/internal/money"
+)
+
+const (
+	StatusRequiresPayment = "requires_payment"
+	StatusSucceeded       = "succeeded"
+	StatusFailed          = "failed"
+	StatusNeedsRefund     = "needs_refund"
+	StatusRefunded        = "refunded"
+)
+
+const (
+	EventPaymentSucceeded = "payment.succeeded"
+	EventPaymentFailed    = "payment.failed"
+	EventPaymentRefunded  = "payment.refunded"
+)
+
+type Provider interface {
+	Name() string
+	CreateIntent(ctx context.Context, request IntentRequest) (Intent, error)
+	Capture(ctx context.Context, intentID string, amount money.Amount) (Intent, error)
+	Refund(ctx context.Context, intentID string, amount money.Amount) (Intent, error)
+	VerifyWebhook(payload []byte, header http.Header) (Event, error)
+}
+
+type IntentRequest struct {
+	Reference   string
+	OrderID     string
+	Amount      money.Amount
+	Currency    string
+	Email       string
+	Description string
+}
+
+type Intent struct {
+	ID           string
+	Status       string
+	ClientSecret string
+	Amount       money.Amount
+	Currency     string
+}
+
+type Event struct {
+	ID       string
+	Type     string
+	IntentID string
+}
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrInvalidEvent     = errors.New("invalid webhook event")
+)
@@ -0,0 +1,268 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"store-serverless/internal/order"
+)
+
+const pgUniqueViolation = "23505"
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) GetActive(ctx context.Context, orderID string) (Payment, error) {
+	p, err := scanPayment(r.db.QueryRowContext(ctx, `
+		SELECT `+paymentColumns+`
+		FROM payments
+		WHERE order_id = $1 AND status IN ('requires_payment', 'succeeded')
+	`, orderID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Payment{}, err
+		}
+		return Payment{}, fmt.Errorf("get active payment: %w", err)
+	}
+	return p, nil
+}
+
+func (r *Repository) Create(ctx context.Context, p Payment) (Payment, error) {
+	now := time.Now().UTC()
+	created, err := scanPayment(r.db.QueryRowContext(ctx, `
+		INSERT INTO payments (id, order_id, provider, intent_id, client_secret, status, amount, currency, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+		RETURNING `+paymentColumns+`
+	`, p.ID, p.OrderID, p.Provider, p.IntentID, p.ClientSecret, p.Status, p.Amount, p.Currency, now))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return Payment{}, ErrPaymentInProgress
+		}
+		return Payment{}, fmt.Errorf("insert payment: %w", err)
+	}
+	return created, nil
+}
+
+func (r *Repository) Apply(ctx context.Context, provider, intentID, status string, event *Event, actor string) (*Payment, string, error) {
+	now := time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("begin apply payment tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, p, err := applyStatus(ctx, tx, provider, intentID, status, actor, now)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if event != nil {
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO payment_events (provider, event_id, type, intent_id, result, received_at)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (provider, event_id) DO NOTHING
+		`, provider, event.ID, event.Type, event.IntentID, result, now)
+		if err != nil {
+			return nil, "", fmt.Errorf("insert payment event: %w", err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return nil, "", fmt.Errorf("insert payment event rows affected: %w", err)
+		}
+		if affected == 0 {
+			_ = tx.Rollback()
+			p, err := r.getByIntent(ctx, provider, intentID)
+			if err != nil {
+				return nil, "", err
+			}
+			return p, ResultDuplicate, nil
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, "", fmt.Errorf("commit apply payment tx: %w", err)
+	}
+
+	return p, result, nil
+}
+
+func applyStatus(ctx context.Context, tx *sql.Tx, provider, intentID, status, actor string, now time.Time) (string, *Payment, error) {
+	if status == "" {
+		return ResultIgnored, nil, nil
+	}
+
+	p, err := scanPayment(tx.QueryRowContext(ctx, `
+		SELECT `+paymentColumns+`
+		FROM payments
+		WHERE provider = $1 AND intent_id = $2
+		FOR UPDATE
+	`, provider, intentID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ResultIgnored, nil, nil
+		}
+		return "", nil, fmt.Errorf("lock payment: %w", err)
+	}
+	previous := p.Status
+
+	var orderStatus string
+	var openSiblings []string
+	siblingPaid := false
+	if status == StatusSucceeded {
+		if err := tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, p.OrderID).Scan(&orderStatus); err != nil {
+			return "", nil, fmt.Errorf("lock payment order: %w", err)
+		}
+		openSiblings, siblingPaid, err = lockSiblings(ctx, tx, p)
+		if err != nil {
+			return "", nil, err
+		}
+	}
+
+	next := resolveStatus(previous, status, orderStatus, siblingPaid)
+	if next == "" {
+		return ResultIgnored, &p, nil
+	}
+
+	if next == StatusSucceeded && len(openSiblings) > 0 {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE payments
+			SET status = $2, updated_at = $3
+			WHERE id = ANY($1::uuid[])
+		`, openSiblings, StatusFailed, now); err != nil {
+			return "", nil, fmt.Errorf("fail superseded payments: %w", err)
+		}
+	}
+
+	p, err = scanPayment(tx.QueryRowContext(ctx, `
+		UPDATE payments
+		SET status = $2, updated_at = $3
+		WHERE id = $1
+		RETURNING `+paymentColumns+`
+	`, p.ID, next, now))
+	if err != nil {
+		return "", nil, fmt.Errorf("update payment status: %w", err)
+	}
+
+	if next == StatusNeedsRefund {
+		return ResultNeedsRefund, &p, nil
+	}
+	if previous == StatusNeedsRefund {
+		return ResultProcessed, &p, nil
+	}
+
+	orderStatus = orderStatusFor(next)
+	if orderStatus == "" {
+		return ResultProcessed, &p, nil
+	}
+
+	err = order.TransitionTx(ctx, tx, p.OrderID, orderStatus, "payment "+p.IntentID+" "+next, &actor)
+	var transitionErr order.ErrInvalidTransition
+	if errors.As(err, &transitionErr) {
+		return ResultOrderUnchanged, &p, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	return ResultProcessed, &p, nil
+}
+
+func lockSiblings(ctx context.Context, tx *sql.Tx, p Payment) ([]string, bool, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, status
+		FROM payments
+		WHERE order_id = $1 AND id <> $2 AND status IN ('requires_payment', 'succeeded')
+		FOR UPDATE
+	`, p.OrderID, p.ID)
+	if err != nil {
+		return nil, false, fmt.Errorf("lock sibling payments: %w", err)
+	}
+	defer rows.Close()
+
+	open := make([]string, 0)
+	paid := false
+	for rows.Next() {
+		var id, status string
+		if err := rows.Scan(&id, &status); err != nil {
+			return nil, false, fmt.Errorf("scan sibling payment: %w", err)
+		}
+		if status == StatusSucceeded {
+			paid = true
+			continue
+		}
+		open = append(open, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("iterate sibling payments: %w", err)
+	}
+
+	return open, paid, nil
+}
+
+func resolveStatus(current, target, orderStatus string, siblingPaid bool) string {
+	if !slices.Contains(statusTransitions[current], target) {
+		return ""
+	}
+	if target == StatusSucceeded && (orderStatus != order.StatusPending || siblingPaid) {
+		return StatusNeedsRefund
+	}
+	return target
+}
+
+func (r *Repository) getByIntent(ctx context.Context, provider, intentID string) (*Payment, error) {
+	p, err := scanPayment(r.db.QueryRowContext(ctx, `
+		SELECT `+paymentColumns+`
+		FROM payments
+		WHERE provider = $1 AND intent_id = $2
+	`, provider, intentID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("get payment by intent: %w", err)
+	}
+	return &p, nil
+}
+
+func orderStatusFor(status string) string {
+	switch status {
+	case StatusSucceeded:
+		return order.StatusPaid
+	case StatusRefunded:
+		return order.StatusRefunded
+	default:
+		return ""
+	}
+}
+
+const paymentColumns = `id, order_id, provider, intent_id, client_secret, status, amount, currency, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPayment(row rowScanner) (Payment, error) {
+	var p Payment
+	if err := row.Scan(&p.ID, &p.OrderID, &p.Provider, &p.IntentID, &p.ClientSecret, &p.Status, &p.Amount, &p.Currency, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return Payment{}, err
+	}
+	p.CreatedAt = p.CreatedAt.UTC()
+	p.UpdatedAt = p.UpdatedAt.UTC()
+	return p, nil
+}
+
+var ErrPaymentInProgress = errors.New("order already has an active payment")
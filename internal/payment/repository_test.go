@@ -0,0 +1,53 @@
+package payment
+
+import (
+	"testing"
+
+	"store-serverless/internal/order"
+)
+
+func TestResolveStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		current     string
+		target      string
+		orderStatus string
+		siblingPaid bool
+		want        string
+	}{
+		{"pending order is paid", StatusRequiresPayment, StatusSucceeded, order.StatusPending, false, StatusSucceeded},
+		{"failure is recorded", StatusRequiresPayment, StatusFailed, "", false, StatusFailed},
+		{"late success after failure", StatusFailed, StatusSucceeded, order.StatusPending, false, StatusSucceeded},
+		{"late success when another payment won", StatusFailed, StatusSucceeded, order.StatusPaid, true, StatusNeedsRefund},
+		{"success on cancelled order", StatusRequiresPayment, StatusSucceeded, order.StatusCancelled, false, StatusNeedsRefund},
+		{"success on already paid order", StatusRequiresPayment, StatusSucceeded, order.StatusPaid, false, StatusNeedsRefund},
+		{"refund of succeeded payment", StatusSucceeded, StatusRefunded, "", false, StatusRefunded},
+		{"refund of unmatched payment", StatusNeedsRefund, StatusRefunded, "", false, StatusRefunded},
+		{"repeated success is ignored", StatusSucceeded, StatusSucceeded, order.StatusPaid, false, ""},
+		{"failure after success is ignored", StatusSucceeded, StatusFailed, "", false, ""},
+		{"refund before payment is ignored", StatusRequiresPayment, StatusRefunded, "", false, ""},
+		{"refunded is final", StatusRefunded, StatusSucceeded, order.StatusRefunded, false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveStatus(tt.current, tt.target, tt.orderStatus, tt.siblingPaid); got != tt.want {
+				t.Fatalf("resolveStatus(%q, %q, %q, %v) = %q, want %q", tt.current, tt.target, tt.orderStatus, tt.siblingPaid, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEventStatus(t *testing.T) {
+	cases := map[string]string{
+		EventPaymentSucceeded: StatusSucceeded,
+		EventPaymentFailed:    StatusFailed,
+		EventPaymentRefunded:  StatusRefunded,
+		"payment.disputed":    "",
+	}
+	for eventType, want := range cases {
+		if got := eventStatus(eventType); got != want {
+			t.Fatalf("eventStatus(%q) = %q, want %q", eventType, got, want)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+
+	"store-serverless/internal/order"
+)
+
+type Service struct {
+	repo     *Repository
+	provider Provider
+	orders   *order.Service
+}
+
+func NewService(repo *Repository, provider Provider, orders *order.Service) *Service {
+	return &Service{repo: repo, provider: provider, orders: orders}
+}
+
+func (s *Service) Authorize(orderID, token string) bool {
+	return s.orders.Authorize(orderID, token)
+}
+
+func (s *Service) CreateIntent(ctx context.Context, orderID string) (Payment, bool, error) {
+	o, err := s.orders.Get(ctx, orderID)
+	if err != nil {
+		return Payment{}, false, err
+	}
+	if o.Status != order.StatusPending {
+		return Payment{}, false, ErrOrderNotPayable
+	}
+
+	existing, err := s.repo.GetActive(ctx, orderID)
+	if err == nil {
+		if existing.Status == StatusRequiresPayment && existing.Provider == s.provider.Name() {
+			return existing, false, nil
+		}
+		return Payment{}, false, ErrPaymentInProgress
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return Payment{}, false, err
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return Payment{}, false, fmt.Errorf("generate uuid v7: %w", err)
+	}
+
+	intent, err := s.provider.CreateIntent(ctx, IntentRequest{
+		Reference:   id.String(),
+		OrderID:     o.ID,
+		Amount:      o.Total,
+		Currency:    o.Currency,
+		Email:       o.Customer.Email,
+		Description: "Pedido " + o.ID,
+	})
+	if err != nil {
+		return Payment{}, false, fmt.Errorf("create payment intent: %w", err)
+	}
+
+	p, err := s.repo.Create(ctx, Payment{
+		ID:           id.String(),
+		OrderID:      o.ID,
+		Provider:     s.provider.Name(),
+		IntentID:     intent.ID,
+		ClientSecret: intent.ClientSecret,
+		Status:       StatusRequiresPayment,
+		Amount:       o.Total,
+		Currency:     o.Currency,
+	})
+	if err != nil {
+		return Payment{}, false, err
+	}
+
+	return p, true, nil
+}
+
+func (s *Service) Capture(ctx context.Context, orderID, actor string) (Payment, error) {
+	p, err := s.active(ctx, orderID, StatusRequiresPayment)
+	if err != nil {
+		return Payment{}, err
+	}
+
+	intent, err := s.provider.Capture(ctx, p.IntentID, p.Amount)
+	if err != nil {
+		return Payment{}, fmt.Errorf("capture payment: %w", err)
+	}
+
+	return s.apply(ctx, p, intent.Status, actor)
+}
+
+func (s *Service) Refund(ctx context.Context, orderID, actor string) (Payment, error) {
+	o, err := s.orders.Get(ctx, orderID)
+	if err != nil {
+		return Payment{}, err
+	}
+	if !order.CanTransition(o.Status, order.StatusRefunded) {
+		return Payment{}, order.ErrInvalidTransition{From: o.Status, To: order.StatusRefunded}
+	}
+
+	p, err := s.active(ctx, orderID, StatusSucceeded)
+	if err != nil {
+		return Payment{}, err
+	}
+
+	intent, err := s.provider.Refund(ctx, p.IntentID, p.Amount)
+	if err != nil {
+		return Payment{}, fmt.Errorf("refund payment: %w", err)
+	}
+
+	return s.apply(ctx, p, intent.Status, actor)
+}
+
+func (s *Service) HandleWebhook(ctx context.Context, payload []byte, header http.Header) (WebhookResult, error) {
+	event, err := s.provider.VerifyWebhook(payload, header)
+	if err != nil {
+		return WebhookResult{}, err
+	}
+
+	p, result, err := s.repo.Apply(ctx, s.provider.Name(), event.IntentID, eventStatus(event.Type), &event, s.actor())
+	if err != nil {
+		return WebhookResult{}, err
+	}
+
+	p, err = s.refundUnmatched(ctx, p, s.actor())
+	if err != nil {
+		return WebhookResult{}, err
+	}
+
+	return WebhookResult{Status: result, Payment: p}, nil
+}
+
+func (s *Service) active(ctx context.Context, orderID, status string) (Payment, error) {
+	p, err := s.repo.GetActive(ctx, orderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Payment{}, ErrPaymentNotFound
+		}
+		return Payment{}, err
+	}
+	if p.Status != status || p.Provider != s.provider.Name() {
+		return Payment{}, ErrPaymentNotFound
+	}
+	return p, nil
+}
+
+func (s *Service) apply(ctx context.Context, p Payment, status, actor string) (Payment, error) {
+	updated, _, err := s.repo.Apply(ctx, p.Provider, p.IntentID, status, nil, actor)
+	if err != nil {
+		return Payment{}, err
+	}
+
+	updated, err = s.refundUnmatched(ctx, updated, actor)
+	if err != nil {
+		return Payment{}, err
+	}
+	if updated == nil {
+		return p, nil
+	}
+	return *updated, nil
+}
+
+func (s *Service) refundUnmatched(ctx context.Context, p *Payment, actor string) (*Payment, error) {
+	if p == nil || p.Status != StatusNeedsRefund {
+		return p, nil
+	}
+
+	intent, err := s.provider.Refund(ctx, p.IntentID, p.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("refund unmatched payment %s: %w", p.IntentID, err)
+	}
+
+	updated, _, err := s.repo.Apply(ctx, p.Provider, p.IntentID, intent.Status, nil, actor)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return p, nil
+	}
+	return updated, nil
+}
+
+func (s *Service) actor() string {
+	return "payment:" + s.provider.Name()
+}
+
+func eventStatus(eventType string) string {
+	switch eventType {
+	case EventPaymentSucceeded:
+		return StatusSucceeded
+	case EventPaymentFailed:
+		return StatusFailed
+	case EventPaymentRefunded:
+		return StatusRefunded
+	default:
+		return ""
+	}
+}
+
+var (
+	ErrOrderNotPayable = errors.New("only pending orders can be paid")
+	ErrPaymentNotFound = errors.New("payment not found")
+)
@@ -0,0 +1,126 @@
+package payment
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"store-serverless/internal/db"
+	"store-serverless/internal/order"
+)
+
+func TestWebhooksDriveOrderIdempotently(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	database, err := sql.Open("pgx", dsn)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { _ = database.Close() })
+	if err := db.RunMigrations(database); err != nil {
+		t.Fatalf("run migrations: %v", err)
+	}
+
+	ctx := context.Background()
+	fake := NewFake("webhook-secret")
+	orders := order.NewService(order.NewRepository(database), nil, "order-secret")
+	service := NewService(NewRepository(database), fake, orders)
+	handler := NewHandler(service)
+	orderID := insertPendingOrder(t, database)
+
+	failed, created, err := service.CreateIntent(ctx, orderID)
+	if err != nil || !created {
+		t.Fatalf("create first intent: created=%v err=%v", created, err)
+	}
+	if got := deliver(t, handler, fake, "evt_fail_"+orderID, EventPaymentFailed, failed.IntentID); got != ResultProcessed {
+		t.Fatalf("failed event: got %q", got)
+	}
+
+	retry, created, err := service.CreateIntent(ctx, orderID)
+	if err != nil || !created || retry.IntentID == failed.IntentID {
+		t.Fatalf("create retry intent: created=%v err=%v intent=%s", created, err, retry.IntentID)
+	}
+
+	successID := "evt_ok_" + orderID
+	if got := deliver(t, handler, fake, successID, EventPaymentSucceeded, retry.IntentID); got != ResultProcessed {
+		t.Fatalf("success event: got %q", got)
+	}
+	if got := deliver(t, handler, fake, successID, EventPaymentSucceeded, retry.IntentID); got != ResultDuplicate {
+		t.Fatalf("redelivered success event: got %q", got)
+	}
+
+	o, err := orders.Get(ctx, orderID)
+	if err != nil {
+		t.Fatalf("get order: %v", err)
+	}
+	if o.Status != order.StatusPaid || len(o.History) != 1 {
+		t.Fatalf("order after payment: status=%s history=%d", o.Status, len(o.History))
+	}
+
+	if got := deliver(t, handler, fake, "evt_late_"+orderID, EventPaymentSucceeded, failed.IntentID); got != ResultNeedsRefund {
+		t.Fatalf("late success event: got %q", got)
+	}
+
+	var lateStatus string
+	if err := database.QueryRowContext(ctx, `SELECT status FROM payments WHERE intent_id = $1`, failed.IntentID).Scan(&lateStatus); err != nil {
+		t.Fatalf("read late payment: %v", err)
+	}
+	if lateStatus != StatusRefunded {
+		t.Fatalf("late payment status = %s, want %s", lateStatus, StatusRefunded)
+	}
+
+	o, err = orders.Get(ctx, orderID)
+	if err != nil {
+		t.Fatalf("get order: %v", err)
+	}
+	if o.Status != order.StatusPaid {
+		t.Fatalf("order status after late success = %s, want %s", o.Status, order.StatusPaid)
+	}
+}
+
+func insertPendingOrder(t *testing.T, database *sql.DB) string {
+	t.Helper()
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		t.Fatalf("generate uuid: %v", err)
+	}
+	if _, err := database.Exec(`
+		INSERT INTO orders (id, status, currency, total, item_count, customer_name, customer_email, shipping_address)
+		VALUES ($1, 'pending', 'PEN', 15.00, 1, 'Test', 'test@example.com', 'Av. Prueba 123')
+	`, id.String()); err != nil {
+		t.Fatalf("insert order: %v", err)
+	}
+	return id.String()
+}
+
+func deliver(t *testing.T, handler *Handler, fake *Fake, eventID, eventType, intentID string) string {
+	t.Helper()
+
+	body := fmt.Sprintf(`{"id":%q,"type":%q,"intent_id":%q}`, eventID, eventType, intentID)
+	req := httptest.NewRequest(http.MethodPost, "/payments/webhook", strings.NewReader(body))
+	req.Header.Set(fakeSignatureHeader, fake.Sign([]byte(body)))
+	rec := httptest.NewRecorder()
+	handler.Webhook(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("webhook %s: status %d body %s", eventID, rec.Code, rec.Body.String())
+	}
+	var result WebhookResult
+	if err := json.NewDecoder(rec.Body).Decode(&result); err != nil {
+		t.Fatalf("decode webhook result: %v", err)
+	}
+	return result.Status
+}
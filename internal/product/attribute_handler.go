@@ -0,0 +1,254 @@
+package product
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+)
+
+const (
+	attributeFilterPrefix  = "attr."
+	maxAttributeFilters    = 10
+	maxAttributeFilterVals = 20
+)
+
+func (h *Handler) ListAttributes(w http.ResponseWriter, r *http.Request) {
+	definitions, err := h.repo.ListAttributes(r.Context())
+	if err != nil {
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to list attributes")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"items": definitions})
+}
+
+func (h *Handler) CreateAttribute(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Key string `json:"key"`
+		AttributeDefinitionInput
+	}
+	if !decodeAttributeBody(w, r, &body) {
+		return
+	}
+
+	key := strings.TrimSpace(body.Key)
+	if !attributeKeyRegex.MatchString(key) {
+		writeError(w, http.StatusBadRequest, "key must be a lowercase identifier of at most 40 characters")
+		return
+	}
+	input, ok := checkAttributeInput(w, body.AttributeDefinitionInput)
+	if !ok {
+		return
+	}
+
+	d, err := h.repo.CreateAttribute(r.Context(), key, input)
+	if err != nil {
+		if errors.Is(err, ErrAttributeExists) {
+			writeError(w, http.StatusConflict, "attribute already exists")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to create attribute")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, d)
+}
+
+func (h *Handler) UpdateAttribute(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	if !attributeKeyRegex.MatchString(key) {
+		writeError(w, http.StatusBadRequest, "invalid attribute key")
+		return
+	}
+
+	var body AttributeDefinitionInput
+	if !decodeAttributeBody(w, r, &body) {
+		return
+	}
+	input, ok := checkAttributeInput(w, body)
+	if !ok {
+		return
+	}
+
+	d, err := h.repo.UpdateAttribute(r.Context(), key, input)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "attribute not found")
+			return
+		}
+		if errors.Is(err, ErrAttributeInUse) {
+			writeError(w, http.StatusConflict, "products use values that the new definition would not allow")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to update attribute")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, d)
+}
+
+func (h *Handler) DeleteAttribute(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("key")
+	if !attributeKeyRegex.MatchString(key) {
+		writeError(w, http.StatusBadRequest, "invalid attribute key")
+		return
+	}
+
+	if err := h.repo.DeleteAttribute(r.Context(), key); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "attribute not found")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to delete attribute")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) checkAttributes(w http.ResponseWriter, r *http.Request, attributes map[string]any) bool {
+	if len(attributes) == 0 {
+		return true
+	}
+
+	schema, err := h.repo.AttributeSchema(r.Context())
+	if err != nil {
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to load attribute schema")
+		return false
+	}
+	if err := validateAttributes(schema, attributes); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return false
+	}
+	return true
+}
+
+func (h *Handler) parseAttributeFilters(w http.ResponseWriter, r *http.Request) ([]AttributeFilter, bool) {
+	query := r.URL.Query()
+	names := make([]string, 0)
+	for name := range query {
+		if strings.HasPrefix(name, attributeFilterPrefix) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, true
+	}
+	slices.Sort(names)
+
+	schema, err := h.repo.AttributeSchema(r.Context())
+	if err != nil {
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to load attribute schema")
+		return nil, false
+	}
+
+	filters := make(map[string]*AttributeFilter)
+	for _, name := range names {
+		key, bound, _ := strings.Cut(strings.TrimPrefix(name, attributeFilterPrefix), ".")
+		definition, ok := schema[key]
+		if !ok || !definition.Filterable {
+			writeError(w, http.StatusBadRequest, "unknown attribute filter: "+key)
+			return nil, false
+		}
+
+		filter, ok := filters[key]
+		if !ok {
+			if len(filters) == maxAttributeFilters {
+				writeError(w, http.StatusBadRequest, "too many attribute filters")
+				return nil, false
+			}
+			filter = &AttributeFilter{Key: key}
+			filters[key] = filter
+		}
+
+		values := query[name]
+		if len(values) > maxAttributeFilterVals {
+			writeError(w, http.StatusBadRequest, "too many values for attribute "+key)
+			return nil, false
+		}
+
+		switch bound {
+		case "":
+			for _, raw := range values {
+				value, err := parseAttributeFilterValue(definition, strings.TrimSpace(raw))
+				if err != nil {
+					writeError(w, http.StatusBadRequest, "attribute "+key+" must be a valid "+definition.Type+" value")
+					return nil, false
+				}
+				filter.Values = append(filter.Values, value)
+			}
+		case "min", "max":
+			if definition.Type != AttributeNumber || len(values) != 1 {
+				writeError(w, http.StatusBadRequest, "attr."+key+"."+bound+" is only valid once on number attributes")
+				return nil, false
+			}
+			number, err := strconv.ParseFloat(strings.TrimSpace(values[0]), 64)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "attr."+key+"."+bound+" must be a number")
+				return nil, false
+			}
+			if bound == "min" {
+				filter.Min = &number
+			} else {
+				filter.Max = &number
+			}
+		default:
+			writeError(w, http.StatusBadRequest, "unknown attribute filter: "+name)
+			return nil, false
+		}
+	}
+
+	result := make([]AttributeFilter, 0, len(filters))
+	for _, key := range slices.Sorted(maps.Keys(filters)) {
+		result = append(result, *filters[key])
+	}
+	return result, true
+}
+
+func parseAttributeFilterValue(definition AttributeDefinition, raw string) (any, error) {
+	switch definition.Type {
+	case AttributeNumber:
+		return strconv.ParseFloat(raw, 64)
+	case AttributeBoolean:
+		return strconv.ParseBool(raw)
+	default:
+		if raw == "" {
+			return nil, errors.New("empty value")
+		}
+		return raw, nil
+	}
+}
+
+func decodeAttributeBody(w http.ResponseWriter, r *http.Request, target any) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return false
+	}
+	return true
+}
+
+func checkAttributeInput(w http.ResponseWriter, input AttributeDefinitionInput) (AttributeDefinitionInput, bool) {
+	input = normalizeAttributeInput(input)
+	if err := validateAttributeInput(input); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return AttributeDefinitionInput{}, false
+	}
+	return input, true
+}
@@ -0,0 +1,276 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const attributeColumns = `key, label, type, allowed_values, unit, filterable, position, created_at, updated_at`
+
+func (r *Repository) ListAttributes(ctx context.Context) ([]AttributeDefinition, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+attributeColumns+`
+		FROM attribute_definitions
+		ORDER BY position ASC, key ASC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query attribute definitions: %w", err)
+	}
+	defer rows.Close()
+
+	definitions := make([]AttributeDefinition, 0)
+	for rows.Next() {
+		d, err := scanAttributeDefinition(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan attribute definition: %w", err)
+		}
+		definitions = append(definitions, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate attribute definitions: %w", err)
+	}
+
+	return definitions, nil
+}
+
+func (r *Repository) AttributeSchema(ctx context.Context) (map[string]AttributeDefinition, error) {
+	definitions, err := r.ListAttributes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := make(map[string]AttributeDefinition, len(definitions))
+	for _, d := range definitions {
+		schema[d.Key] = d
+	}
+	return schema, nil
+}
+
+func (r *Repository) CreateAttribute(ctx context.Context, key string, input AttributeDefinitionInput) (AttributeDefinition, error) {
+	allowed, err := json.Marshal(input.AllowedValues)
+	if err != nil {
+		return AttributeDefinition{}, fmt.Errorf("encode allowed values: %w", err)
+	}
+
+	now := time.Now().UTC()
+	d, err := scanAttributeDefinition(r.db.QueryRowContext(ctx, `
+		INSERT INTO attribute_definitions (key, label, type, allowed_values, unit, filterable, position, created_at, updated_at)
+		VALUES ($1, $2, $3, $4::jsonb, $5, $6, $7, $8, $8)
+		RETURNING `+attributeColumns+`
+	`, key, input.Label, input.Type, string(allowed), input.Unit, input.Filterable, input.Position, now))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return AttributeDefinition{}, ErrAttributeExists
+		}
+		return AttributeDefinition{}, fmt.Errorf("insert attribute definition: %w", err)
+	}
+
+	return d, nil
+}
+
+func (r *Repository) UpdateAttribute(ctx context.Context, key string, input AttributeDefinitionInput) (AttributeDefinition, error) {
+	allowed, err := json.Marshal(input.AllowedValues)
+	if err != nil {
+		return AttributeDefinition{}, fmt.Errorf("encode allowed values: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return AttributeDefinition{}, fmt.Errorf("begin attribute update tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var currentType string
+	if err := tx.QueryRowContext(ctx, `SELECT type FROM attribute_definitions WHERE key = $1 FOR UPDATE`, key).Scan(&currentType); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return AttributeDefinition{}, err
+		}
+		return AttributeDefinition{}, fmt.Errorf("lock attribute definition: %w", err)
+	}
+
+	var conflicting bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM products
+			WHERE attributes ? $1
+				AND ($2 <> $3 OR ($2 = 'enum' AND NOT (attributes ->> $1 = ANY($4::text[]))))
+		)
+	`, key, input.Type, currentType, input.AllowedValues).Scan(&conflicting)
+	if err != nil {
+		return AttributeDefinition{}, fmt.Errorf("check attribute usage: %w", err)
+	}
+	if conflicting {
+		return AttributeDefinition{}, ErrAttributeInUse
+	}
+
+	d, err := scanAttributeDefinition(tx.QueryRowContext(ctx, `
+		UPDATE attribute_definitions
+		SET label = $2, type = $3, allowed_values = $4::jsonb, unit = $5, filterable = $6, position = $7, updated_at = $8
+		WHERE key = $1
+		RETURNING `+attributeColumns+`
+	`, key, input.Label, input.Type, string(allowed), input.Unit, input.Filterable, input.Position, time.Now().UTC()))
+	if err != nil {
+		return AttributeDefinition{}, fmt.Errorf("update attribute definition: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return AttributeDefinition{}, fmt.Errorf("commit attribute update tx: %w", err)
+	}
+
+	return d, nil
+}
+
+func (r *Repository) DeleteAttribute(ctx context.Context, key string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin attribute delete tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM attribute_definitions WHERE key = $1`, key)
+	if err != nil {
+		return fmt.Errorf("delete attribute definition: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	now := time.Now().UTC()
+	rows, err := tx.QueryContext(ctx, `
+		WITH affected AS (
+			SELECT id, attributes -> $1 AS removed
+			FROM products
+			WHERE attributes ? $1
+			FOR UPDATE
+		)
+		UPDATE products AS p
+		SET attributes = p.attributes - $1, updated_at = $2
+		FROM affected a
+		WHERE p.id = a.id
+		RETURNING `+productColumns+`, a.removed
+	`, key, now)
+	if err != nil {
+		return fmt.Errorf("remove attribute from products: %w", err)
+	}
+	defer rows.Close()
+
+	type strippedProduct struct {
+		product Product
+		removed any
+	}
+	stripped := make([]strippedProduct, 0)
+	for rows.Next() {
+		var raw []byte
+		p, err := scanProduct(rows, &raw)
+		if err != nil {
+			return fmt.Errorf("scan stripped product: %w", err)
+		}
+		var removed any
+		if err := json.Unmarshal(raw, &removed); err != nil {
+			return fmt.Errorf("decode removed attribute: %w", err)
+		}
+		stripped = append(stripped, strippedProduct{product: p, removed: removed})
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate stripped products: %w", err)
+	}
+
+	for _, s := range stripped {
+		before := s.product.input()
+		before.Attributes = maps.Clone(before.Attributes)
+		before.Attributes[key] = s.removed
+		if err := recordRevision(ctx, tx, s.product, RevisionUpdate, inputChanges(&before, s.product.input()), now); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit attribute delete tx: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) Facets(ctx context.Context, params ListParams) ([]Facet, error) {
+	var q listQuery
+	filters := params.Attributes
+	params.Attributes = nil
+	q.applyFilters(params)
+	for _, filter := range filters {
+		q.where("(a.key = " + q.arg(filter.Key) + " OR " + q.attributeCondition(filter) + ")")
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT d.key, d.label, d.type, d.unit, a.value, COUNT(*)
+		FROM products p
+		CROSS JOIN LATERAL jsonb_each(p.attributes) AS a(key, value)
+		JOIN attribute_definitions d ON d.key = a.key AND d.filterable
+		`+q.whereClause()+`
+		GROUP BY d.key, d.label, d.type, d.unit, d.position, a.value
+		ORDER BY d.position ASC, d.key ASC, COUNT(*) DESC, a.value ASC
+	`, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("query facets: %w", err)
+	}
+	defer rows.Close()
+
+	facets := make([]Facet, 0)
+	for rows.Next() {
+		var facet Facet
+		var raw []byte
+		var count int
+		if err := rows.Scan(&facet.Key, &facet.Label, &facet.Type, &facet.Unit, &raw, &count); err != nil {
+			return nil, fmt.Errorf("scan facet: %w", err)
+		}
+		var value any
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, fmt.Errorf("decode facet value: %w", err)
+		}
+
+		if len(facets) == 0 || facets[len(facets)-1].Key != facet.Key {
+			facet.Values = make([]FacetValue, 0)
+			facets = append(facets, facet)
+		}
+		last := &facets[len(facets)-1]
+		last.Values = append(last.Values, FacetValue{Value: value, Count: count})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate facets: %w", err)
+	}
+
+	return facets, nil
+}
+
+func scanAttributeDefinition(row rowScanner) (AttributeDefinition, error) {
+	var d AttributeDefinition
+	var allowed []byte
+	if err := row.Scan(&d.Key, &d.Label, &d.Type, &allowed, &d.Unit, &d.Filterable, &d.Position, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		return AttributeDefinition{}, err
+	}
+	if err := json.Unmarshal(allowed, &d.AllowedValues); err != nil {
+		return AttributeDefinition{}, fmt.Errorf("decode allowed values: %w", err)
+	}
+	if d.AllowedValues == nil {
+		d.AllowedValues = []string{}
+	}
+	return d, nil
+}
+
+var (
+	ErrAttributeExists = errors.New("attribute already exists")
+	ErrAttributeInUse  = errors.New("attribute values are in use")
+)
@@ -0,0 +1,164 @@
+package product
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func productETag(p Product) string {
+	tag := storageVersion(p)
+	if variant := representationVariant(p); variant != "" {
+		tag += "-" + hashHex(variant)[:16]
+	}
+	return `"` + tag + `"`
+}
+
+func pageETag(page Page) string {
+	parts := make([]string, 0, len(page.Items)+len(page.Facets)+1)
+	for _, p := range page.Items {
+		parts = append(parts, productVersion(p))
+	}
+	if page.NextCursor != nil {
+		parts = append(parts, *page.NextCursor)
+	}
+	for _, facet := range page.Facets {
+		parts = append(parts, facet.Key+":"+facet.Label+":"+facet.Unit)
+		for _, value := range facet.Values {
+			parts = append(parts, fmt.Sprintf("%s=%v:%d", facet.Key, value.Value, value.Count))
+		}
+	}
+	return entityTag(parts...)
+}
+
+func productVersion(p Product) string {
+	return p.ID + "@" + strconv.FormatInt(p.UpdatedAt.UnixMicro(), 10) + representationVariant(p)
+}
+
+func storageVersion(p Product) string {
+	return hashHex(p.ID + "@" + strconv.FormatInt(p.UpdatedAt.UnixMicro(), 10))[:32]
+}
+
+func representationVariant(p Product) string {
+	var variant string
+	if p.Pricing.OnSale {
+		variant += ":" + p.Pricing.EffectivePrice.String()
+	}
+	if p.Locale != "" && p.Locale != defaultLocale {
+		variant += "/" + p.Locale
+	}
+	if p.Pricing.LowestPrice30d != p.Price {
+		variant += "~" + p.Pricing.LowestPrice30d.String()
+	}
+	if p.RatingCount > 0 {
+		variant += "*" + strconv.FormatFloat(p.RatingAverage, 'f', 2, 64) + "/" + strconv.Itoa(p.RatingCount)
+	}
+	return variant
+}
+
+func versionMatches(header string, p Product) bool {
+	version := storageVersion(p)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+		candidate = strings.Trim(candidate, `"`)
+		if stored, _, _ := strings.Cut(candidate, "-"); stored == version {
+			return true
+		}
+	}
+	return false
+}
+
+func lastModified(products ...Product) time.Time {
+	var latest time.Time
+	for _, p := range products {
+		for _, t := range []time.Time{p.UpdatedAt, p.changedAt, p.ratingUpdatedAt} {
+			if t.After(latest) {
+				latest = t
+			}
+		}
+	}
+	return latest
+}
+
+func (p *Product) computeChangedAt(now, catalogChangedAt time.Time, lowestExpiredAt *time.Time) {
+	p.changedAt = catalogChangedAt
+	passed := []*time.Time{p.PublishAt, lowestExpiredAt}
+	if p.SalePrice != nil {
+		passed = append(passed, p.SaleStartsAt, p.SaleEndsAt)
+	}
+	for _, t := range passed {
+		if t != nil && !t.After(now) && t.After(p.changedAt) {
+			p.changedAt = *t
+		}
+	}
+}
+
+func entityTag(parts ...string) string {
+	h := sha256.New()
+	for _, part := range parts {
+		_, _ = h.Write([]byte(part))
+		_, _ = h.Write([]byte{0})
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+func hashHex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeCacheable(w http.ResponseWriter, r *http.Request, etag string, modifiedAt time.Time, data any) {
+	w.Header().Set("ETag", etag)
+	w.Header().Add("Vary", "Authorization, Accept-Language")
+	w.Header().Set("Cache-Control", "no-cache")
+	if !modifiedAt.IsZero() {
+		w.Header().Set("Last-Modified", modifiedAt.UTC().Format(http.TimeFormat))
+	}
+
+	if notModified(r, etag, modifiedAt) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, data)
+}
+
+func notModified(r *http.Request, etag string, modifiedAt time.Time) bool {
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		return etagListMatches(header, etag)
+	}
+
+	header := r.Header.Get("If-Modified-Since")
+	if header == "" || modifiedAt.IsZero() {
+		return false
+	}
+	since, err := http.ParseTime(header)
+	if err != nil {
+		return false
+	}
+
+	return !modifiedAt.Truncate(time.Second).After(since)
+}
+
+func etagListMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+		if strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,231 @@
+package product
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	exportFormatCSV    = "csv"
+	exportFormatNDJSON = "ndjson"
+	exportFormatXLSX   = "xlsx"
+)
+
+var exportContentTypes = map[string]string{
+	exportFormatCSV:    "text/csv; charset=utf-8",
+	exportFormatNDJSON: "application/x-ndjson",
+	exportFormatXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+var exportColumns = []string{
+	"id", "slug", "sku", "title", "description", "status", "publish_at",
+	"price", "effective_price", "discount_percent", "currency",
+	"stock_quantity", "in_stock", "variant_count", "image_url", "attributes",
+	"created_at", "updated_at",
+}
+
+var exportNumericColumns = map[string]bool{
+	"price":            true,
+	"effective_price":  true,
+	"discount_percent": true,
+	"stock_quantity":   true,
+	"variant_count":    true,
+}
+
+type exportWriter interface {
+	write(p Product) error
+	close() error
+}
+
+func newExportWriter(format string, w io.Writer) (exportWriter, error) {
+	switch format {
+	case exportFormatNDJSON:
+		return &ndjsonExportWriter{encoder: json.NewEncoder(w)}, nil
+	case exportFormatXLSX:
+		return newXLSXExportWriter(w)
+	default:
+		writer := csv.NewWriter(w)
+		return &csvExportWriter{writer: writer}, writer.Write(exportColumns)
+	}
+}
+
+func exportRecord(p Product) []string {
+	stock := ""
+	if p.StockQuantity != nil {
+		stock = strconv.Itoa(*p.StockQuantity)
+	}
+	sku := ""
+	if p.SKU != nil {
+		sku = *p.SKU
+	}
+	attributes := ""
+	if len(p.Attributes) > 0 {
+		encoded, _ := json.Marshal(p.Attributes)
+		attributes = string(encoded)
+	}
+
+	return []string{
+		p.ID,
+		p.Slug,
+		sku,
+		p.Title,
+		p.Description,
+		p.Status,
+		formatExportTime(p.PublishAt),
+		p.Price.String(),
+		p.Pricing.EffectivePrice.String(),
+		strconv.Itoa(p.Pricing.DiscountPercent),
+		p.Currency,
+		stock,
+		strconv.FormatBool(p.InStock),
+		strconv.Itoa(len(p.Variants)),
+		p.ImageURL,
+		attributes,
+		p.CreatedAt.UTC().Format(time.RFC3339),
+		p.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+func formatExportTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.UTC().Format(time.RFC3339)
+}
+
+type csvExportWriter struct {
+	writer *csv.Writer
+}
+
+func (e *csvExportWriter) write(p Product) error {
+	record := exportRecord(p)
+	for i, value := range record {
+		if !exportNumericColumns[exportColumns[i]] {
+			record[i] = escapeSpreadsheetFormula(value)
+		}
+	}
+	if err := e.writer.Write(record); err != nil {
+		return err
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func (e *csvExportWriter) close() error {
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+func escapeSpreadsheetFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+type ndjsonExportWriter struct {
+	encoder *json.Encoder
+}
+
+func (e *ndjsonExportWriter) write(p Product) error {
+	return e.encoder.Encode(p)
+}
+
+func (e *ndjsonExportWriter) close() error {
+	return nil
+}
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRootRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Productos" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+type xlsxExportWriter struct {
+	archive *zip.Writer
+	sheet   *bufio.Writer
+}
+
+func newXLSXExportWriter(w io.Writer) (*xlsxExportWriter, error) {
+	archive := zip.NewWriter(w)
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	}
+	for _, part := range parts {
+		file, err := archive.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(file, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	file, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+
+	e := &xlsxExportWriter{archive: archive, sheet: bufio.NewWriter(file)}
+	if _, err := e.sheet.WriteString(xlsxSheetStart); err != nil {
+		return nil, err
+	}
+	if err := e.writeRow(exportColumns, false); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *xlsxExportWriter) write(p Product) error {
+	return e.writeRow(exportRecord(p), true)
+}
+
+func (e *xlsxExportWriter) writeRow(values []string, typed bool) error {
+	e.sheet.WriteString("<row>")
+	for i, value := range values {
+		switch {
+		case value == "":
+			e.sheet.WriteString("<c/>")
+		case typed && exportNumericColumns[exportColumns[i]]:
+			e.sheet.WriteString("<c><v>")
+			e.sheet.WriteString(value)
+			e.sheet.WriteString("</v></c>")
+		default:
+			e.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+			if err := xml.EscapeText(e.sheet, []byte(value)); err != nil {
+				return err
+			}
+			e.sheet.WriteString("</t></is></c>")
+		}
+	}
+	_, err := e.sheet.WriteString("</row>")
+	return err
+}
+
+func (e *xlsxExportWriter) close() error {
+	if _, err := e.sheet.WriteString(xlsxSheetEnd); err != nil {
+		return err
+	}
+	if err := e.sheet.Flush(); err != nil {
+		return err
+	}
+	return e.archive.Close()
+}
@@ -0,0 +1,82 @@
+package product
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+)
+
+func (h *Handler) ExportProducts(w http.ResponseWriter, r *http.Request) {
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format == "" {
+		format = exportFormatCSV
+	}
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		writeError(w, http.StatusBadRequest, "format must be one of csv, ndjson, xlsx")
+		return
+	}
+
+	params, ok := parseListParams(w, r)
+	if !ok {
+		return
+	}
+	if params.Attributes, ok = h.parseAttributeFilters(w, r); !ok {
+		return
+	}
+
+	var writer exportWriter
+	start := func() error {
+		filename := "productos-" + time.Now().UTC().Format("20060102") + "." + format
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(http.StatusOK)
+
+		var err error
+		writer, err = newExportWriter(format, w)
+		return err
+	}
+
+	controller := http.NewResponseController(w)
+	err := h.repo.Export(r.Context(), params, func(batch []Product) error {
+		if writer == nil {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		for _, p := range batch {
+			if err := writer.write(p); err != nil {
+				return err
+			}
+		}
+		_ = controller.Flush()
+		return nil
+	})
+	if err != nil {
+		if writer != nil {
+			sentry.CaptureException(err)
+			return
+		}
+		if errors.Is(err, ErrInvalidSort) {
+			writeError(w, http.StatusBadRequest, "invalid sort")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to export products")
+		return
+	}
+
+	if writer == nil {
+		if err := start(); err != nil {
+			sentry.CaptureException(err)
+			return
+		}
+	}
+	if err := writer.close(); err != nil {
+		sentry.CaptureException(err)
+	}
+}
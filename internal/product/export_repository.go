@@ -0,0 +1,84 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+)
+
+const exportBatchSize = 500
+
+func (r *Repository) Export(ctx context.Context, params ListParams, fn func([]Product) error) error {
+	sort, ok := lookupSort(params.Sort)
+	if !ok {
+		return ErrInvalidSort
+	}
+
+	var q listQuery
+	q.applyFilters(params)
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true, Isolation: sql.LevelRepeatableRead})
+	if err != nil {
+		return fmt.Errorf("begin export tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		DECLARE product_export NO SCROLL CURSOR FOR
+		SELECT `+productColumns+`
+		FROM products p
+		`+q.whereClause()+`
+		ORDER BY `+sort.orderBy(), q.args...); err != nil {
+		return fmt.Errorf("declare export cursor: %w", err)
+	}
+
+	for {
+		batch, err := fetchExportBatch(ctx, tx)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		if err := hydrateWith(ctx, tx, productPointers(batch)...); err != nil {
+			return err
+		}
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < exportBatchSize {
+			break
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `CLOSE product_export`); err != nil {
+		return fmt.Errorf("close export cursor: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func fetchExportBatch(ctx context.Context, tx *sql.Tx) ([]Product, error) {
+	rows, err := tx.QueryContext(ctx, `FETCH `+strconv.Itoa(exportBatchSize)+` FROM product_export`)
+	if err != nil {
+		return nil, fmt.Errorf("fetch export batch: %w", err)
+	}
+	defer rows.Close()
+
+	batch := make([]Product, 0, exportBatchSize)
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan exported product: %w", err)
+		}
+		batch = append(batch, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate export batch: %w", err)
+	}
+
+	return batch, nil
+}
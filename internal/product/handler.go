@@ -5,24 +5,28 @@ import (
 	"database/sql"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
-	"net/url"
-	"regexp"
+	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 
 	"github.com/getsentry/sentry-go"
 	"github.com/google/uuid"
-)
 
-var allowedURLChars = regexp.MustCompile(`^[A-Za-z0-9\-._~:/?#\[\]@!$&'()*+,;=%]+$`)
-var allowedHost = regexp.MustCompile(`^[A-Za-z0-9.-]+$`)
+	"store-serverless/internal/auth"
+	"store-serverless/internal/money"
+)
 
 const maxJSONBodyBytes = 1 << 20
 
 type Handler struct {
-	repo     *Repository
-	uploader ImageUploader
+	repo              *Repository
+	uploader          ImageUploader
+	requireIfMatch    bool
+	importMaxRows     int
+	importConcurrency int
 }
 
 type ImageUploader interface {
@@ -30,18 +34,174 @@ type ImageUploader interface {
 }
 
 func NewHandler(repo *Repository, uploader ImageUploader) *Handler {
-	return &Handler{repo: repo, uploader: uploader}
+	return &Handler{
+		repo:              repo,
+		uploader:          uploader,
+		importMaxRows:     defaultImportMaxRows,
+		importConcurrency: defaultImportConcurrency,
+	}
+}
+
+func (h *Handler) WithConcurrencyConfig(requireIfMatch bool) {
+	h.requireIfMatch = requireIfMatch
+}
+
+func (h *Handler) WithImportConfig(maxRows, concurrency int) {
+	if maxRows > 0 {
+		h.importMaxRows = maxRows
+	}
+	if concurrency > 0 {
+		h.importConcurrency = concurrency
+	}
 }
 
 func (h *Handler) ListProducts(w http.ResponseWriter, r *http.Request) {
-	products, err := h.repo.List(r.Context())
+	params, ok := parseListParams(w, r)
+	if !ok {
+		return
+	}
+	if params.Attributes, ok = h.parseAttributeFilters(w, r); !ok {
+		return
+	}
+
+	page, err := h.repo.List(r.Context(), params)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		if errors.Is(err, ErrInvalidSort) {
+			writeError(w, http.StatusBadRequest, "invalid sort")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to list products")
+		return
+	}
+
+	page.Facets, err = h.repo.Facets(r.Context(), params)
+	if err != nil {
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to list products")
+		return
+	}
+	if err := h.localize(w, r, productPointers(page.Items)...); err != nil {
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to list products")
+		return
+	}
+
+	modifiedAt, err := h.repo.ListModifiedAt(r.Context())
 	if err != nil {
 		sentry.CaptureException(err)
 		writeError(w, http.StatusInternalServerError, "failed to list products")
 		return
 	}
+	if itemsAt := lastModified(page.Items...); itemsAt.After(modifiedAt) {
+		modifiedAt = itemsAt
+	}
+
+	writeCacheable(w, r, pageETag(page), modifiedAt, page)
+}
+
+func (h *Handler) GetProduct(w http.ResponseWriter, r *http.Request) {
+	key := r.PathValue("id")
+	id := key
+	bySlug := false
+	if _, err := uuid.Parse(key); err != nil {
+		if len(key) > maxSlugLength || !slugRegex.MatchString(key) {
+			writeError(w, http.StatusBadRequest, "invalid product id")
+			return
+		}
+
+		id, err = h.repo.ResolveSlug(r.Context(), key)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				writeError(w, http.StatusNotFound, "product not found")
+				return
+			}
+			sentry.CaptureException(err)
+			writeError(w, http.StatusInternalServerError, "failed to get product")
+			return
+		}
+		bySlug = true
+	}
+
+	get := h.repo.GetPublished
+	if isAdmin(r) {
+		get = h.repo.GetByID
+	}
+
+	p, err := get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to get product")
+		return
+	}
 
-	writeJSON(w, http.StatusOK, products)
+	if bySlug && p.Slug != key {
+		location := "/products/" + p.Slug
+		if r.URL.RawQuery != "" {
+			location += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, location, http.StatusMovedPermanently)
+		return
+	}
+
+	if err := h.localize(w, r, &p); err != nil {
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to get product")
+		return
+	}
+
+	writeCacheable(w, r, productETag(p), lastModified(p), p)
+}
+
+func (h *Handler) SearchProducts(w http.ResponseWriter, r *http.Request) {
+	text := strings.TrimSpace(r.URL.Query().Get("q"))
+	if text == "" {
+		writeError(w, http.StatusBadRequest, "q is required")
+		return
+	}
+	if !utf8.ValidString(text) || utf8.RuneCountInString(text) > maxSearchQuery {
+		writeError(w, http.StatusBadRequest, "q is invalid")
+		return
+	}
+
+	params, ok := parsePageParams(w, r)
+	if !ok {
+		return
+	}
+	if params.Attributes, ok = h.parseAttributeFilters(w, r); !ok {
+		return
+	}
+
+	page, err := h.repo.Search(r.Context(), text, params)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to search products")
+		return
+	}
+
+	products := make([]*Product, 0, len(page.Items))
+	for i := range page.Items {
+		products = append(products, &page.Items[i].Product)
+	}
+	if err := h.localize(w, r, products...); err != nil {
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to search products")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
 }
 
 func (h *Handler) CreateProduct(w http.ResponseWriter, r *http.Request) {
@@ -51,9 +211,12 @@ func (h *Handler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 	}
 
 	input, ok := parseInput(w, r)
-	if !ok {
+	if !ok || !h.checkAttributes(w, r, input.Attributes) {
 		return
 	}
+	if input.Status == "" {
+		input.Status = StatusDraft
+	}
 
 	uploadedURL, err := h.uploader.UploadImage(r.Context(), input.ImageURL)
 	if err != nil {
@@ -65,11 +228,16 @@ func (h *Handler) CreateProduct(w http.ResponseWriter, r *http.Request) {
 
 	p, err := h.repo.Create(r.Context(), input)
 	if err != nil {
+		if errors.Is(err, ErrDuplicateSKU) {
+			writeError(w, http.StatusConflict, "sku already exists")
+			return
+		}
 		sentry.CaptureException(err)
 		writeError(w, http.StatusInternalServerError, "failed to create product")
 		return
 	}
 
+	w.Header().Set("ETag", productETag(p))
 	writeJSON(w, http.StatusCreated, p)
 }
 
@@ -86,6 +254,11 @@ func (h *Handler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 	}
 
 	input, ok := parseInput(w, r)
+	if !ok || !h.checkAttributes(w, r, input.Attributes) {
+		return
+	}
+
+	expectedUpdatedAt, ok := h.checkIfMatch(w, r, id)
 	if !ok {
 		return
 	}
@@ -98,17 +271,106 @@ func (h *Handler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
 	}
 	input.ImageURL = uploadedURL
 
-	p, err := h.repo.Update(r.Context(), id, input)
+	p, err := h.repo.Update(r.Context(), id, input, expectedUpdatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "product not found")
 			return
 		}
+		if errors.Is(err, ErrPreconditionFailed) {
+			writeError(w, http.StatusPreconditionFailed, "product has been modified")
+			return
+		}
+		if errors.Is(err, ErrDuplicateSKU) {
+			writeError(w, http.StatusConflict, "sku already exists")
+			return
+		}
 		writeError(w, http.StatusInternalServerError, "failed to update product")
 		sentry.CaptureException(err)
 		return
 	}
 
+	w.Header().Set("ETag", productETag(p))
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (h *Handler) PatchProduct(w http.ResponseWriter, r *http.Request) {
+	if h.uploader == nil {
+		writeError(w, http.StatusInternalServerError, "image uploader is not configured")
+		return
+	}
+
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	patch, ok := parseMergePatch(w, r)
+	if !ok {
+		return
+	}
+
+	ifMatch := strings.TrimSpace(r.Header.Get("If-Match"))
+	if ifMatch == "" && h.requireIfMatch {
+		writeError(w, http.StatusPreconditionRequired, "If-Match header is required")
+		return
+	}
+
+	current, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to get product")
+		return
+	}
+	if ifMatch != "" && !versionMatches(ifMatch, current) {
+		writeError(w, http.StatusPreconditionFailed, "product has been modified")
+		return
+	}
+
+	input, err := applyMergePatch(current, patch)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if _, ok := patch["attributes"]; ok && !h.checkAttributes(w, r, input.Attributes) {
+		return
+	}
+
+	if input.ImageURL != current.ImageURL {
+		uploadedURL, err := h.uploader.UploadImage(r.Context(), input.ImageURL)
+		if err != nil {
+			sentry.CaptureException(err)
+			writeError(w, http.StatusBadGateway, "failed to upload image")
+			return
+		}
+		input.ImageURL = uploadedURL
+	}
+
+	p, err := h.repo.Update(r.Context(), id, input, current.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		if errors.Is(err, ErrPreconditionFailed) {
+			writeError(w, http.StatusPreconditionFailed, "product has been modified")
+			return
+		}
+		if errors.Is(err, ErrDuplicateSKU) {
+			writeError(w, http.StatusConflict, "sku already exists")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "failed to update product")
+		sentry.CaptureException(err)
+		return
+	}
+
+	w.Header().Set("ETag", productETag(p))
 	writeJSON(w, http.StatusOK, p)
 }
 
@@ -119,12 +381,21 @@ func (h *Handler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.repo.Delete(r.Context(), id)
+	expectedUpdatedAt, ok := h.checkIfMatch(w, r, id)
+	if !ok {
+		return
+	}
+
+	err := h.repo.Delete(r.Context(), id, expectedUpdatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			writeError(w, http.StatusNotFound, "product not found")
 			return
 		}
+		if errors.Is(err, ErrPreconditionFailed) {
+			writeError(w, http.StatusPreconditionFailed, "product has been modified")
+			return
+		}
 		writeError(w, http.StatusInternalServerError, "failed to delete product")
 		sentry.CaptureException(err)
 		return
@@ -133,6 +404,35 @@ func (h *Handler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+func (h *Handler) checkIfMatch(w http.ResponseWriter, r *http.Request, id string) (time.Time, bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" {
+		if h.requireIfMatch {
+			writeError(w, http.StatusPreconditionRequired, "If-Match header is required")
+			return time.Time{}, false
+		}
+		return time.Time{}, true
+	}
+
+	current, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "product not found")
+			return time.Time{}, false
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to check product version")
+		return time.Time{}, false
+	}
+
+	if !versionMatches(header, current) {
+		writeError(w, http.StatusPreconditionFailed, "product has been modified")
+		return time.Time{}, false
+	}
+
+	return current.UpdatedAt, true
+}
+
 func parseInput(w http.ResponseWriter, r *http.Request) (ProductInput, bool) {
 	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
 
@@ -140,57 +440,129 @@ func parseInput(w http.ResponseWriter, r *http.Request) (ProductInput, bool) {
 	decoder := json.NewDecoder(r.Body)
 	decoder.DisallowUnknownFields()
 	if err := decoder.Decode(&input); err != nil {
+		if amountErr, ok := amountError(err); ok {
+			writeError(w, http.StatusBadRequest, amountErr.Error())
+			return ProductInput{}, false
+		}
 		writeError(w, http.StatusBadRequest, "invalid json body")
 		return ProductInput{}, false
 	}
 
-	input.Title = strings.TrimSpace(input.Title)
-	input.Description = strings.TrimSpace(input.Description)
-	input.ImageURL = strings.TrimSpace(input.ImageURL)
-
-	if input.Title == "" {
-		writeError(w, http.StatusBadRequest, "title is required")
+	input = normalizeInput(input)
+	if err := validateInput(input); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
 		return ProductInput{}, false
 	}
-	if !utf8.ValidString(input.Title) || len(input.Title) > 150 {
-		writeError(w, http.StatusBadRequest, "title is invalid")
-		return ProductInput{}, false
+
+	return input, true
+}
+
+func parseListParams(w http.ResponseWriter, r *http.Request) (ListParams, bool) {
+	params, ok := parsePageParams(w, r)
+	if !ok {
+		return ListParams{}, false
 	}
-	if !utf8.ValidString(input.Description) || len(input.Description) > 1000 {
-		writeError(w, http.StatusBadRequest, "description is invalid")
-		return ProductInput{}, false
+
+	params.Sort = strings.TrimSpace(r.URL.Query().Get("sort"))
+	if params.Sort == "" {
+		params.Sort = defaultSort
 	}
-	if input.ImageURL == "" {
-		writeError(w, http.StatusBadRequest, "image_url is required")
-		return ProductInput{}, false
+	if _, ok := lookupSort(params.Sort); !ok {
+		writeError(w, http.StatusBadRequest, "sort must be one of created_at, price, title (prefix with - for descending)")
+		return ListParams{}, false
 	}
-	if len(input.ImageURL) > 500 || !isASCII(input.ImageURL) {
-		writeError(w, http.StatusBadRequest, "image_url contains invalid characters")
-		return ProductInput{}, false
+
+	return params, true
+}
+
+func parsePageParams(w http.ResponseWriter, r *http.Request) (ListParams, bool) {
+	query := r.URL.Query()
+	params := ListParams{
+		Limit:         defaultListLimit,
+		Cursor:        strings.TrimSpace(query.Get("cursor")),
+		IncludeHidden: isAdmin(r),
 	}
-	if !allowedURLChars.MatchString(input.ImageURL) {
-		writeError(w, http.StatusBadRequest, "image_url contains invalid characters")
-		return ProductInput{}, false
+
+	if raw := strings.TrimSpace(query.Get("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxListLimit {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxListLimit))
+			return ListParams{}, false
+		}
+		params.Limit = limit
 	}
-	parsedURL, err := url.ParseRequestURI(input.ImageURL)
-	if err != nil || parsedURL.Scheme == "" || parsedURL.Host == "" {
-		writeError(w, http.StatusBadRequest, "image_url must be a valid link")
-		return ProductInput{}, false
+
+	var ok bool
+	if params.MinPrice, ok = parsePriceParam(w, query.Get("min_price"), "min_price"); !ok {
+		return ListParams{}, false
 	}
-	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
-		writeError(w, http.StatusBadRequest, "image_url must start with http or https")
-		return ProductInput{}, false
+	if params.MaxPrice, ok = parsePriceParam(w, query.Get("max_price"), "max_price"); !ok {
+		return ListParams{}, false
 	}
-	if parsedURL.User != nil || !allowedHost.MatchString(parsedURL.Hostname()) {
-		writeError(w, http.StatusBadRequest, "image_url host is invalid")
-		return ProductInput{}, false
+	if params.MinPrice != nil && params.MaxPrice != nil && *params.MinPrice > *params.MaxPrice {
+		writeError(w, http.StatusBadRequest, "min_price must be <= max_price")
+		return ListParams{}, false
 	}
-	if input.Price < 0 {
-		writeError(w, http.StatusBadRequest, "price must be >= 0")
-		return ProductInput{}, false
+
+	if params.CreatedAfter, ok = parseTimeParam(w, query.Get("created_after"), "created_after"); !ok {
+		return ListParams{}, false
+	}
+	if params.CreatedBefore, ok = parseTimeParam(w, query.Get("created_before"), "created_before"); !ok {
+		return ListParams{}, false
 	}
 
-	return input, true
+	params.Category = strings.TrimSpace(query.Get("category"))
+	if len(params.Category) > 100 {
+		writeError(w, http.StatusBadRequest, "category is invalid")
+		return ListParams{}, false
+	}
+	if raw := strings.TrimSpace(query.Get("include_descendants")); raw != "" {
+		descendants, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "include_descendants must be true or false")
+			return ListParams{}, false
+		}
+		params.Descendants = descendants
+	}
+
+	params.Status = strings.ToLower(strings.TrimSpace(query.Get("status")))
+	if params.Status != "" && !validStatus(params.Status) {
+		writeError(w, http.StatusBadRequest, errInvalidStatus.Error())
+		return ListParams{}, false
+	}
+
+	return params, true
+}
+
+func isAdmin(r *http.Request) bool {
+	_, ok := auth.Subject(r.Context())
+	return ok
+}
+
+func parsePriceParam(w http.ResponseWriter, raw, name string) (*money.Amount, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, true
+	}
+	value, err := money.Parse(raw)
+	if err != nil || value < 0 {
+		writeError(w, http.StatusBadRequest, name+" must be an amount >= 0 with at most 2 decimals")
+		return nil, false
+	}
+	return &value, true
+}
+
+func parseTimeParam(w http.ResponseWriter, raw, name string) (*time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, true
+	}
+	value, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, name+" must be an RFC3339 timestamp")
+		return nil, false
+	}
+	return &value, true
 }
 
 func writeJSON(w http.ResponseWriter, status int, data any) {
@@ -202,12 +574,3 @@ func writeJSON(w http.ResponseWriter, status int, data any) {
 func writeError(w http.ResponseWriter, status int, message string) {
 	writeJSON(w, status, map[string]string{"error": message})
 }
-
-func isASCII(value string) bool {
-	for i := 0; i < len(value); i++ {
-		if value[i] < 32 || value[i] > 126 {
-			return false
-		}
-	}
-	return true
-}
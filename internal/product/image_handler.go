@@ -0,0 +1,148 @@
+package product
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) AddImage(w http.ResponseWriter, r *http.Request) {
+	if h.uploader == nil {
+		writeError(w, http.StatusInternalServerError, "image uploader is not configured")
+		return
+	}
+
+	productID := r.PathValue("id")
+	if _, err := uuid.Parse(productID); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
+
+	var input ImageInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+
+	input = normalizeImageInput(input)
+	if err := validateImageInput(input); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	uploadedURL, err := h.uploader.UploadImage(r.Context(), input.ImageURL)
+	if err != nil {
+		sentry.CaptureException(err)
+		writeError(w, http.StatusBadGateway, "failed to upload image")
+		return
+	}
+	input.ImageURL = uploadedURL
+
+	img, err := h.repo.AddImage(r.Context(), productID, input)
+	if err != nil {
+		if errors.Is(err, ErrProductNotFound) {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		if errors.Is(err, ErrTooManyImages) {
+			writeError(w, http.StatusConflict, "a product can have at most 20 images")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to add image")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, img)
+}
+
+func (h *Handler) ReorderImages(w http.ResponseWriter, r *http.Request) {
+	productID := r.PathValue("id")
+	if _, err := uuid.Parse(productID); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
+
+	var body struct {
+		ImageIDs []string `json:"image_ids"`
+	}
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+	if body.ImageIDs == nil || len(body.ImageIDs) > maxProductImages {
+		writeError(w, http.StatusBadRequest, "image_ids must list the product images")
+		return
+	}
+
+	seen := make(map[string]struct{}, len(body.ImageIDs))
+	for _, id := range body.ImageIDs {
+		if _, err := uuid.Parse(id); err != nil {
+			writeError(w, http.StatusBadRequest, "image_ids contains an invalid id")
+			return
+		}
+		if _, dup := seen[id]; dup {
+			writeError(w, http.StatusBadRequest, "image_ids must be unique")
+			return
+		}
+		seen[id] = struct{}{}
+	}
+
+	images, err := h.repo.ReorderImages(r.Context(), productID, body.ImageIDs)
+	if err != nil {
+		if errors.Is(err, ErrProductNotFound) {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		if errors.Is(err, ErrImageOrderMismatch) {
+			writeError(w, http.StatusConflict, "image_ids must list every image of the product exactly once")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to reorder images")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"items": images})
+}
+
+func (h *Handler) DeleteImage(w http.ResponseWriter, r *http.Request) {
+	productID := r.PathValue("id")
+	imageID := r.PathValue("imageID")
+	if _, err := uuid.Parse(productID); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+	if _, err := uuid.Parse(imageID); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid image id")
+		return
+	}
+
+	if err := h.repo.DeleteImage(r.Context(), productID, imageID); err != nil {
+		if errors.Is(err, ErrProductNotFound) {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "image not found")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to delete image")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,186 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const imageColumns = `i.id, i.product_id, i.url, i.alt_text, i.width, i.height, i.position, i.created_at`
+
+func (r *Repository) AddImage(ctx context.Context, productID string, input ImageInput) (Image, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return Image{}, fmt.Errorf("generate uuid v7: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Image{}, fmt.Errorf("begin add image tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := touchProduct(ctx, tx, productID, now); err != nil {
+		return Image{}, err
+	}
+
+	var count, nextPosition int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(MAX(position) + 1, 0)
+		FROM product_images
+		WHERE product_id = $1
+	`, productID).Scan(&count, &nextPosition); err != nil {
+		return Image{}, fmt.Errorf("count images: %w", err)
+	}
+	if count >= maxProductImages {
+		return Image{}, ErrTooManyImages
+	}
+
+	img, err := scanImage(tx.QueryRowContext(ctx, `
+		INSERT INTO product_images AS i (id, product_id, url, alt_text, width, height, position, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+imageColumns+`
+	`, id.String(), productID, input.ImageURL, input.AltText, input.Width, input.Height, nextPosition, now))
+	if err != nil {
+		return Image{}, fmt.Errorf("insert image: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Image{}, fmt.Errorf("commit add image tx: %w", err)
+	}
+
+	return img, nil
+}
+
+func (r *Repository) ReorderImages(ctx context.Context, productID string, imageIDs []string) ([]Image, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin reorder images tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := touchProduct(ctx, tx, productID, time.Now().UTC()); err != nil {
+		return nil, err
+	}
+
+	var matches bool
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COALESCE(array_agg(id ORDER BY id), '{}') = COALESCE((SELECT array_agg(x ORDER BY x) FROM unnest($2::uuid[]) AS x), '{}')
+		FROM product_images
+		WHERE product_id = $1
+	`, productID, imageIDs).Scan(&matches); err != nil {
+		return nil, fmt.Errorf("compare images: %w", err)
+	}
+	if !matches {
+		return nil, ErrImageOrderMismatch
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE product_images AS i
+		SET position = o.ordinality - 1
+		FROM unnest($2::uuid[]) WITH ORDINALITY AS o(id, ordinality)
+		WHERE i.product_id = $1 AND i.id = o.id
+	`, productID, imageIDs); err != nil {
+		return nil, fmt.Errorf("reorder images: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit reorder images tx: %w", err)
+	}
+
+	images, err := loadImages(ctx, r.db, []string{productID})
+	if err != nil {
+		return nil, err
+	}
+	if images[productID] == nil {
+		return []Image{}, nil
+	}
+	return images[productID], nil
+}
+
+func (r *Repository) DeleteImage(ctx context.Context, productID, imageID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin delete image tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := touchProduct(ctx, tx, productID, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM product_images WHERE product_id = $1 AND id = $2`, productID, imageID)
+	if err != nil {
+		return fmt.Errorf("delete image: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit delete image tx: %w", err)
+	}
+
+	return nil
+}
+
+func loadImages(ctx context.Context, q queryer, productIDs []string) (map[string][]Image, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT `+imageColumns+`
+		FROM product_images i
+		WHERE i.product_id = ANY($1::uuid[])
+		ORDER BY i.product_id, i.position ASC, i.id ASC
+	`, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query images: %w", err)
+	}
+	defer rows.Close()
+
+	images := make(map[string][]Image, len(productIDs))
+	for rows.Next() {
+		img, err := scanImage(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan image: %w", err)
+		}
+		images[img.ProductID] = append(images[img.ProductID], img)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate images: %w", err)
+	}
+
+	return images, nil
+}
+
+func scanImage(row rowScanner) (Image, error) {
+	var img Image
+	var width, height sql.NullInt64
+	if err := row.Scan(&img.ID, &img.ProductID, &img.URL, &img.AltText, &width, &height, &img.Position, &img.CreatedAt); err != nil {
+		return Image{}, err
+	}
+	if width.Valid {
+		value := int(width.Int64)
+		img.Width = &value
+	}
+	if height.Valid {
+		value := int(height.Int64)
+		img.Height = &value
+	}
+	return img, nil
+}
+
+var (
+	ErrTooManyImages      = errors.New("product has too many images")
+	ErrImageOrderMismatch = errors.New("image ids do not match the product images")
+)
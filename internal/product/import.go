@@ -0,0 +1,346 @@
+package product
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"store-serverless/internal/money"
+)
+
+const (
+	importFormatCSV    = "csv"
+	importFormatNDJSON = "ndjson"
+	maxImportBodyBytes = 10 << 20
+	maxImportLineBytes = 1 << 20
+
+	defaultImportMaxRows     = 1000
+	defaultImportConcurrency = 4
+
+	importActionCreate = "create"
+	importActionUpdate = "update"
+)
+
+var importColumns = []string{
+	"id", "sku", "title", "description", "price", "currency", "image_url",
+	"status", "publish_at", "sale_price", "sale_starts_at", "sale_ends_at",
+	"low_stock_threshold", "allow_backorder", "attributes",
+}
+
+var requiredImportColumns = []string{
+	"title", "price", "currency", "status", "low_stock_threshold", "allow_backorder",
+}
+
+var (
+	errTooManyImportRows = errors.New("too many rows")
+	errEmptyImport       = errors.New("import file has no rows")
+)
+
+type importRecord struct {
+	ID string `json:"id"`
+	ProductInput
+}
+
+type importRow struct {
+	line   int
+	record importRecord
+	fields map[string]bool
+	err    error
+}
+
+func importFormat(r *http.Request) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format"))) {
+	case importFormatCSV:
+		return importFormatCSV, true
+	case importFormatNDJSON:
+		return importFormatNDJSON, true
+	case "":
+	default:
+		return "", false
+	}
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return "", false
+	}
+	switch mediaType {
+	case "text/csv":
+		return importFormatCSV, true
+	case "application/x-ndjson", "application/ndjson", "application/jsonl", "application/x-jsonlines":
+		return importFormatNDJSON, true
+	}
+	return "", false
+}
+
+func readImportRows(format string, body io.Reader, maxRows int) ([]importRow, error) {
+	var rows []importRow
+	var err error
+	if format == importFormatCSV {
+		rows, err = readCSVRows(body, maxRows)
+	} else {
+		rows, err = readNDJSONRows(body, maxRows)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, errEmptyImport
+	}
+	return rows, nil
+}
+
+func readCSVRows(body io.Reader, maxRows int) ([]importRow, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errEmptyImport
+		}
+		return nil, fmt.Errorf("invalid csv: %w", err)
+	}
+
+	fields := make(map[string]bool, len(header))
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		if !slices.Contains(importColumns, column) {
+			return nil, fmt.Errorf("unknown column: %s", column)
+		}
+		if fields[column] {
+			return nil, fmt.Errorf("duplicate column: %s", column)
+		}
+		fields[column] = true
+		header[i] = column
+	}
+
+	rows := make([]importRow, 0)
+	for {
+		values, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+		if len(rows) == maxRows {
+			return nil, errTooManyImportRows
+		}
+
+		line, _ := reader.FieldPos(0)
+		record, present, err := parseCSVRecord(header, values)
+		rows = append(rows, importRow{line: line, record: record, fields: present, err: err})
+	}
+
+	return rows, nil
+}
+
+func parseCSVRecord(header, values []string) (importRecord, map[string]bool, error) {
+	var record importRecord
+	fields := make(map[string]bool, len(header))
+	for i, column := range header {
+		value := strings.TrimSpace(values[i])
+		if value == "" {
+			if !slices.Contains(requiredImportColumns, column) {
+				fields[column] = true
+			}
+			continue
+		}
+		fields[column] = true
+
+		var err error
+		switch column {
+		case "id":
+			record.ID = value
+		case "sku":
+			record.SKU = &value
+		case "title":
+			record.Title = value
+		case "description":
+			record.Description = value
+		case "price":
+			record.Price, err = money.Parse(value)
+		case "currency":
+			record.Currency = value
+		case "image_url":
+			record.ImageURL = value
+		case "status":
+			record.Status = value
+		case "publish_at":
+			record.PublishAt, err = parseImportTime(value)
+		case "sale_price":
+			var amount money.Amount
+			amount, err = money.Parse(value)
+			record.SalePrice = &amount
+		case "sale_starts_at":
+			record.SaleStartsAt, err = parseImportTime(value)
+		case "sale_ends_at":
+			record.SaleEndsAt, err = parseImportTime(value)
+		case "low_stock_threshold":
+			record.LowStockThreshold, err = strconv.Atoi(value)
+			if err != nil {
+				err = errors.New("must be an integer")
+			}
+		case "allow_backorder":
+			record.AllowBackorder, err = strconv.ParseBool(value)
+			if err != nil {
+				err = errors.New("must be true or false")
+			}
+		case "attributes":
+			if json.Unmarshal([]byte(value), &record.Attributes) != nil {
+				err = errors.New("must be a json object")
+			}
+		}
+		if err != nil {
+			if amountErr, ok := amountError(err); ok {
+				return importRecord{}, nil, amountErr
+			}
+			return importRecord{}, nil, fmt.Errorf("%s %s", column, err.Error())
+		}
+	}
+	return record, fields, nil
+}
+
+func parseImportTime(value string) (*time.Time, error) {
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, errors.New("must be an RFC3339 timestamp")
+	}
+	return &parsed, nil
+}
+
+func readNDJSONRows(body io.Reader, maxRows int) ([]importRow, error) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxImportLineBytes)
+
+	rows := make([]importRow, 0)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+		if len(rows) == maxRows {
+			return nil, errTooManyImportRows
+		}
+
+		var record importRecord
+		decoder := json.NewDecoder(bytes.NewReader(raw))
+		decoder.DisallowUnknownFields()
+		err := decoder.Decode(&record)
+		if err != nil {
+			if amountErr, ok := amountError(err); ok {
+				err = amountErr
+			} else {
+				err = errors.New("invalid json")
+			}
+		}
+
+		var keys map[string]json.RawMessage
+		fields := make(map[string]bool)
+		if err == nil && json.Unmarshal(raw, &keys) == nil {
+			for _, key := range slices.Sorted(maps.Keys(keys)) {
+				if slices.Contains(requiredImportColumns, key) && isJSONNull(keys[key]) {
+					err = errors.New(key + " cannot be null")
+					break
+				}
+				fields[key] = true
+			}
+		}
+		rows = append(rows, importRow{line: line, record: record, fields: fields, err: err})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read ndjson: %w", err)
+	}
+
+	return rows, nil
+}
+
+func validateImportRows(rows []importRow) {
+	ids := make(map[string]int)
+	skus := make(map[string]int)
+	for i := range rows {
+		row := &rows[i]
+		if row.err != nil {
+			continue
+		}
+
+		if row.record.ID != "" {
+			parsed, err := uuid.Parse(row.record.ID)
+			if err != nil {
+				row.err = errors.New("invalid product id")
+				continue
+			}
+			row.record.ID = parsed.String()
+		}
+
+		row.record.ProductInput = normalizeInput(row.record.ProductInput)
+
+		if row.record.ID != "" {
+			if first, dup := ids[row.record.ID]; dup {
+				row.err = fmt.Errorf("id already used on line %d", first)
+				continue
+			}
+			ids[row.record.ID] = row.line
+		}
+		if row.record.SKU != nil {
+			if first, dup := skus[*row.record.SKU]; dup {
+				row.err = fmt.Errorf("sku already used on line %d", first)
+				continue
+			}
+			skus[*row.record.SKU] = row.line
+		}
+	}
+}
+
+func mergeImportRecord(current ProductInput, row importRow) ProductInput {
+	record := row.record.ProductInput
+	for field := range row.fields {
+		switch field {
+		case "sku":
+			current.SKU = record.SKU
+		case "title":
+			current.Title = record.Title
+		case "description":
+			current.Description = record.Description
+		case "price":
+			current.Price = record.Price
+		case "currency":
+			current.Currency = record.Currency
+		case "image_url":
+			current.ImageURL = record.ImageURL
+		case "status":
+			current.Status = record.Status
+		case "publish_at":
+			current.PublishAt = record.PublishAt
+		case "sale_price":
+			current.SalePrice = record.SalePrice
+		case "sale_starts_at":
+			current.SaleStartsAt = record.SaleStartsAt
+		case "sale_ends_at":
+			current.SaleEndsAt = record.SaleEndsAt
+		case "low_stock_threshold":
+			current.LowStockThreshold = record.LowStockThreshold
+		case "allow_backorder":
+			current.AllowBackorder = record.AllowBackorder
+		case "attributes":
+			current.Attributes = record.Attributes
+		}
+	}
+	return normalizeInput(current)
+}
@@ -0,0 +1,245 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"golang.org/x/sync/errgroup"
+)
+
+type importPlan struct {
+	row    importRow
+	result ImportRowResult
+	target importTarget
+	upload bool
+}
+
+func (h *Handler) ImportProducts(w http.ResponseWriter, r *http.Request) {
+	if h.uploader == nil {
+		writeError(w, http.StatusInternalServerError, "image uploader is not configured")
+		return
+	}
+
+	format, ok := importFormat(r)
+	if !ok {
+		writeError(w, http.StatusUnsupportedMediaType, "Content-Type must be text/csv or application/x-ndjson")
+		return
+	}
+
+	dryRun := false
+	if raw := strings.TrimSpace(r.URL.Query().Get("dry_run")); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "dry_run must be true or false")
+			return
+		}
+		dryRun = parsed
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportBodyBytes)
+	rows, err := readImportRows(format, r.Body, h.importMaxRows)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		switch {
+		case errors.As(err, &maxBytesErr):
+			writeError(w, http.StatusRequestEntityTooLarge, "import file is too large")
+		case errors.Is(err, errTooManyImportRows):
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("import file has more than %d rows", h.importMaxRows))
+		default:
+			writeError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	validateImportRows(rows)
+
+	plans, err := h.planImport(r.Context(), rows)
+	if err != nil {
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to import products")
+		return
+	}
+
+	report := ImportReport{DryRun: dryRun, Total: len(plans)}
+	for _, plan := range plans {
+		if plan.result.Error != "" {
+			report.Failed++
+		}
+	}
+	if dryRun || report.Failed > 0 {
+		status := http.StatusOK
+		if !dryRun {
+			status = http.StatusUnprocessableEntity
+		}
+		report.Rows = importResults(plans)
+		writeJSON(w, status, report)
+		return
+	}
+
+	h.uploadImportImages(r.Context(), plans)
+
+	for i := range plans {
+		plan := &plans[i]
+		if plan.result.Error != "" {
+			continue
+		}
+		if err := h.writeImportRow(r.Context(), plan); err != nil {
+			plan.result.Error = err.Error()
+		}
+	}
+
+	report.Failed = 0
+	for _, plan := range plans {
+		switch {
+		case plan.result.Error != "":
+			report.Failed++
+		case plan.result.Action == importActionCreate:
+			report.Created++
+		case plan.result.Action == importActionUpdate:
+			report.Updated++
+		}
+	}
+
+	report.Rows = importResults(plans)
+	writeJSON(w, http.StatusOK, report)
+}
+
+func (h *Handler) planImport(ctx context.Context, rows []importRow) ([]importPlan, error) {
+	ids := make([]string, 0)
+	skus := make([]string, 0)
+	for _, row := range rows {
+		if row.err != nil {
+			continue
+		}
+		if row.record.ID != "" {
+			ids = append(ids, row.record.ID)
+		}
+		if row.record.SKU != nil {
+			skus = append(skus, *row.record.SKU)
+		}
+	}
+
+	byID, bySKU, err := h.repo.matchImportTargets(ctx, ids, skus)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := h.repo.AttributeSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	plans := make([]importPlan, 0, len(rows))
+	for _, row := range rows {
+		plan := importPlan{row: row, result: ImportRowResult{Line: row.line, SKU: row.record.SKU}}
+		if row.err != nil {
+			plan.result.Error = row.err.Error()
+			plans = append(plans, plan)
+			continue
+		}
+
+		var found bool
+		switch {
+		case row.record.ID != "":
+			plan.target, found = byID[row.record.ID]
+			if !found {
+				plan.result.Error = "product not found"
+				plans = append(plans, plan)
+				continue
+			}
+		case row.record.SKU != nil:
+			plan.target, found = bySKU[*row.record.SKU]
+		}
+
+		if found {
+			plan.result.Action = importActionUpdate
+			plan.result.ID = plan.target.ID
+			plan.row.record.ProductInput = mergeImportRecord(plan.target.Input, row)
+			plan.upload = plan.row.record.ImageURL != plan.target.Input.ImageURL
+		} else {
+			plan.result.Action = importActionCreate
+			plan.upload = true
+		}
+
+		input := plan.row.record.ProductInput
+		err := validateInput(input)
+		if err == nil {
+			err = validateAttributes(schema, input.Attributes)
+		}
+		if err != nil {
+			plan.result.Error = err.Error()
+		}
+		plans = append(plans, plan)
+	}
+
+	return plans, nil
+}
+
+func (h *Handler) uploadImportImages(ctx context.Context, plans []importPlan) {
+	var group errgroup.Group
+	group.SetLimit(h.importConcurrency)
+
+	for i := range plans {
+		plan := &plans[i]
+		if !plan.upload || plan.result.Error != "" {
+			continue
+		}
+		group.Go(func() error {
+			uploadedURL, err := h.uploader.UploadImage(ctx, plan.row.record.ImageURL)
+			if err != nil {
+				sentry.CaptureException(err)
+				plan.result.Error = "failed to upload image"
+				return nil
+			}
+			plan.row.record.ImageURL = uploadedURL
+			return nil
+		})
+	}
+
+	_ = group.Wait()
+}
+
+func (h *Handler) writeImportRow(ctx context.Context, plan *importPlan) error {
+	input := plan.row.record.ProductInput
+
+	var p Product
+	var err error
+	if plan.result.Action == importActionUpdate {
+		p, err = h.repo.Update(ctx, plan.target.ID, input, plan.target.UpdatedAt)
+	} else {
+		if input.Status == "" {
+			input.Status = StatusDraft
+		}
+		p, err = h.repo.Create(ctx, input)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrDuplicateSKU):
+			return errors.New("sku already exists")
+		case errors.Is(err, sql.ErrNoRows):
+			return errors.New("product not found")
+		case errors.Is(err, ErrPreconditionFailed):
+			return errors.New("product was modified during the import")
+		default:
+			sentry.CaptureException(err)
+			return errors.New("failed to save product")
+		}
+	}
+
+	plan.result.ID = p.ID
+	return nil
+}
+
+func importResults(plans []importPlan) []ImportRowResult {
+	results := make([]ImportRowResult, 0, len(plans))
+	for _, plan := range plans {
+		results = append(results, plan.result)
+	}
+	return results
+}
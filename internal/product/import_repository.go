@@ -0,0 +1,49 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type importTarget struct {
+	ID        string
+	Input     ProductInput
+	UpdatedAt time.Time
+}
+
+func (r *Repository) matchImportTargets(ctx context.Context, ids, skus []string) (map[string]importTarget, map[string]importTarget, error) {
+	byID := make(map[string]importTarget)
+	bySKU := make(map[string]importTarget)
+	if len(ids) == 0 && len(skus) == 0 {
+		return byID, bySKU, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+productColumns+`
+		FROM products p
+		WHERE p.deleted_at IS NULL AND (p.id = ANY($1::uuid[]) OR p.sku = ANY($2::text[]))
+	`, ids, skus)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query import targets: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("scan import target: %w", err)
+		}
+		target := importTarget{ID: p.ID, Input: p.input(), UpdatedAt: p.UpdatedAt}
+		byID[target.ID] = target
+		if p.SKU != nil {
+			bySKU[*p.SKU] = target
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("iterate import targets: %w", err)
+	}
+
+	return byID, bySKU, nil
+}
@@ -0,0 +1,246 @@
+package product
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"store-serverless/internal/money"
+)
+
+func TestParseCSVRecord(t *testing.T) {
+	header := []string{"id", "sku", "title", "price", "currency", "sale_price", "publish_at", "low_stock_threshold", "allow_backorder", "attributes"}
+
+	tests := []struct {
+		name       string
+		values     []string
+		wantErr    string
+		wantFields []string
+		check      func(t *testing.T, got importRecord)
+	}{
+		{
+			name:       "full row",
+			values:     []string{"", " CRM-1 ", "Crema", "49.90", "pen", "39.90", "2026-07-01T10:00:00Z", "3", "true", `{"spf": 50}`},
+			wantFields: []string{"id", "sku", "title", "price", "currency", "sale_price", "publish_at", "low_stock_threshold", "allow_backorder", "attributes"},
+			check: func(t *testing.T, got importRecord) {
+				if got.SKU == nil || *got.SKU != "CRM-1" || got.Title != "Crema" || got.Price != money.FromMinor(4990) {
+					t.Fatalf("got %+v", got)
+				}
+				if got.SalePrice == nil || *got.SalePrice != money.FromMinor(3990) {
+					t.Fatalf("sale_price = %v", got.SalePrice)
+				}
+				if got.PublishAt == nil || !got.PublishAt.Equal(time.Date(2026, 7, 1, 10, 0, 0, 0, time.UTC)) {
+					t.Fatalf("publish_at = %v", got.PublishAt)
+				}
+				if got.LowStockThreshold != 3 || !got.AllowBackorder || got.Attributes["spf"] != float64(50) {
+					t.Fatalf("got %+v", got)
+				}
+			},
+		},
+		{
+			name:       "blank required columns are not present",
+			values:     []string{"", "", "", "", "", "", "", "", "", ""},
+			wantFields: []string{"id", "sku", "sale_price", "publish_at", "attributes"},
+			check: func(t *testing.T, got importRecord) {
+				if got.SKU != nil || got.SalePrice != nil || got.PublishAt != nil || got.Attributes != nil {
+					t.Fatalf("got %+v, want blank optional values", got)
+				}
+			},
+		},
+		{
+			name:    "price with too many decimals",
+			values:  []string{"", "", "Crema", "49.999", "", "", "", "", "", ""},
+			wantErr: "amounts must have at most 2 decimals",
+		},
+		{
+			name:    "invalid timestamp",
+			values:  []string{"", "", "Crema", "", "", "", "2026-07-01", "", "", ""},
+			wantErr: "publish_at must be an RFC3339 timestamp",
+		},
+		{
+			name:    "invalid integer",
+			values:  []string{"", "", "Crema", "", "", "", "", "tres", "", ""},
+			wantErr: "low_stock_threshold must be an integer",
+		},
+		{
+			name:    "invalid boolean",
+			values:  []string{"", "", "Crema", "", "", "", "", "", "quizás", ""},
+			wantErr: "allow_backorder must be true or false",
+		},
+		{
+			name:    "attributes that are not an object",
+			values:  []string{"", "", "Crema", "", "", "", "", "", "", `[1, 2]`},
+			wantErr: "attributes must be a json object",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, fields, err := parseCSVRecord(header, tt.values)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("parseCSVRecord() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCSVRecord() error = %v", err)
+			}
+			if len(fields) != len(tt.wantFields) {
+				t.Fatalf("fields = %v, want %v", fields, tt.wantFields)
+			}
+			for _, field := range tt.wantFields {
+				if !fields[field] {
+					t.Fatalf("fields = %v, want %v", fields, tt.wantFields)
+				}
+			}
+			tt.check(t, got)
+		})
+	}
+}
+
+func TestMergeImportRecord(t *testing.T) {
+	sku := "CRM-1"
+	salePrice := money.FromMinor(3990)
+	current := ProductInput{
+		SKU:               &sku,
+		Title:             "Crema",
+		Description:       "Protección diaria",
+		Price:             money.FromMinor(4990),
+		Currency:          "PEN",
+		Status:            StatusPublished,
+		SalePrice:         &salePrice,
+		LowStockThreshold: 5,
+		AllowBackorder:    true,
+		Attributes:        map[string]any{"spf": float64(50)},
+	}
+
+	tests := []struct {
+		name  string
+		csv   string
+		check func(t *testing.T, got ProductInput)
+	}{
+		{
+			name: "absent columns are kept",
+			csv:  "id,title\n,Crema FPS 50\n",
+			check: func(t *testing.T, got ProductInput) {
+				if got.Title != "Crema FPS 50" || got.Price != current.Price || got.Description != current.Description || got.SalePrice == nil {
+					t.Fatalf("got %+v", got)
+				}
+			},
+		},
+		{
+			name: "blank price keeps the current price",
+			csv:  "id,price\n,\n",
+			check: func(t *testing.T, got ProductInput) {
+				if got.Price != current.Price {
+					t.Fatalf("price = %s, want %s", got.Price, current.Price)
+				}
+			},
+		},
+		{
+			name: "blank required columns keep their values",
+			csv:  "id,title,currency,status,low_stock_threshold,allow_backorder\n,,,,,\n",
+			check: func(t *testing.T, got ProductInput) {
+				if got.Title != current.Title || got.Currency != current.Currency || got.Status != current.Status {
+					t.Fatalf("got %+v", got)
+				}
+				if got.LowStockThreshold != current.LowStockThreshold || !got.AllowBackorder {
+					t.Fatalf("got %+v", got)
+				}
+			},
+		},
+		{
+			name: "blank optional columns clear their values",
+			csv:  "id,sku,description,sale_price,attributes\n,,,,\n",
+			check: func(t *testing.T, got ProductInput) {
+				if got.SKU != nil || got.Description != "" || got.SalePrice != nil || got.Attributes != nil {
+					t.Fatalf("got %+v, want cleared optional values", got)
+				}
+			},
+		},
+		{
+			name: "present values replace the current ones",
+			csv:  "id,price,currency,allow_backorder\n,59.90,usd,false\n",
+			check: func(t *testing.T, got ProductInput) {
+				if got.Price != money.FromMinor(5990) || got.Currency != "USD" || got.AllowBackorder {
+					t.Fatalf("got %+v", got)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := readCSVRows(strings.NewReader(tt.csv), 10)
+			if err != nil {
+				t.Fatalf("readCSVRows() error = %v", err)
+			}
+			if len(rows) != 1 || rows[0].err != nil {
+				t.Fatalf("rows = %+v", rows)
+			}
+			tt.check(t, mergeImportRecord(current, rows[0]))
+		})
+	}
+}
+
+func TestReadNDJSONRowsRejectsNullRequiredFields(t *testing.T) {
+	body := `{"id": "9f1c1f1e-4b55-4c55-9d38-8d0b9b1a2c10", "price": null}
+{"id": "9f1c1f1e-4b55-4c55-9d38-8d0b9b1a2c11", "sale_price": null}
+`
+	rows, err := readNDJSONRows(strings.NewReader(body), 10)
+	if err != nil {
+		t.Fatalf("readNDJSONRows() error = %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("rows = %+v", rows)
+	}
+	if rows[0].err == nil || rows[0].err.Error() != "price cannot be null" {
+		t.Fatalf("line 1 error = %v, want price cannot be null", rows[0].err)
+	}
+	if rows[1].err != nil || !rows[1].fields["sale_price"] {
+		t.Fatalf("line 2 = %+v, want sale_price present and cleared", rows[1])
+	}
+}
+
+func TestValidateImportRows(t *testing.T) {
+	sku := func(value string) *string { return &value }
+	rows := []importRow{
+		{line: 2, record: importRecord{ID: "9F1C1F1E-4B55-4C55-9D38-8D0B9B1A2C10", ProductInput: ProductInput{Title: " Crema ", SKU: sku(" CRM-1 ")}}},
+		{line: 3, record: importRecord{ID: "9f1c1f1e-4b55-4c55-9d38-8d0b9b1a2c10"}},
+		{line: 4, record: importRecord{ProductInput: ProductInput{SKU: sku("CRM-1")}}},
+		{line: 5, record: importRecord{ID: "no-es-uuid"}},
+		{line: 6, record: importRecord{ProductInput: ProductInput{SKU: sku("  ")}}},
+		{line: 7, record: importRecord{ProductInput: ProductInput{SKU: sku("CRM-2")}}, err: errNullField},
+		{line: 8, record: importRecord{ProductInput: ProductInput{SKU: sku("CRM-2")}}},
+	}
+
+	validateImportRows(rows)
+
+	wantErrs := []string{
+		"",
+		"id already used on line 2",
+		"sku already used on line 2",
+		"invalid product id",
+		"",
+		errNullField.Error(),
+		"",
+	}
+	for i, want := range wantErrs {
+		got := ""
+		if rows[i].err != nil {
+			got = rows[i].err.Error()
+		}
+		if got != want {
+			t.Fatalf("line %d error = %q, want %q", rows[i].line, got, want)
+		}
+	}
+
+	first := rows[0].record
+	if first.ID != "9f1c1f1e-4b55-4c55-9d38-8d0b9b1a2c10" || first.Title != "Crema" || *first.SKU != "CRM-1" || first.Currency != money.DefaultCurrency {
+		t.Fatalf("line 2 was not normalized: %+v", first)
+	}
+	if rows[4].record.SKU != nil {
+		t.Fatalf("line 6 sku = %q, want nil", *rows[4].record.SKU)
+	}
+}
@@ -1,20 +1,387 @@
 package product
 
-import "time"
+import (
+	"time"
+
+	"store-serverless/internal/money"
+)
+
+const (
+	StatusDraft     = "draft"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
 
 type Product struct {
-	ID          string    `json:"id"`
+	ID                string         `json:"id"`
+	Slug              string         `json:"slug"`
+	SKU               *string        `json:"sku"`
+	Title             string         `json:"title"`
+	Description       string         `json:"description"`
+	Locale            string         `json:"locale"`
+	Price             money.Amount   `json:"price"`
+	Currency          string         `json:"currency"`
+	ImageURL          string         `json:"image_url"`
+	Status            string         `json:"status"`
+	PublishAt         *time.Time     `json:"publish_at"`
+	SalePrice         *money.Amount  `json:"sale_price"`
+	SaleStartsAt      *time.Time     `json:"sale_starts_at"`
+	SaleEndsAt        *time.Time     `json:"sale_ends_at"`
+	Pricing           Pricing        `json:"pricing"`
+	Attributes        map[string]any `json:"attributes"`
+	StockQuantity     *int           `json:"stock_quantity"`
+	LowStockThreshold int            `json:"low_stock_threshold"`
+	AllowBackorder    bool           `json:"allow_backorder"`
+	InStock           bool           `json:"in_stock"`
+	AvailableQuantity *int           `json:"available_quantity"`
+	LowStock          bool           `json:"low_stock"`
+	RatingAverage     float64        `json:"rating_average"`
+	RatingCount       int            `json:"rating_count"`
+	Variants          []Variant      `json:"variants"`
+	Images            []Image        `json:"images"`
+	CreatedAt         time.Time      `json:"created_at"`
+	UpdatedAt         time.Time      `json:"updated_at"`
+	DeletedAt         *time.Time     `json:"deleted_at,omitempty"`
+
+	changedAt       time.Time
+	ratingUpdatedAt time.Time
+}
+
+type ProductInput struct {
+	SKU               *string        `json:"sku"`
+	Title             string         `json:"title"`
+	Description       string         `json:"description"`
+	Price             money.Amount   `json:"price"`
+	Currency          string         `json:"currency"`
+	ImageURL          string         `json:"image_url"`
+	LowStockThreshold int            `json:"low_stock_threshold"`
+	AllowBackorder    bool           `json:"allow_backorder"`
+	Status            string         `json:"status"`
+	PublishAt         *time.Time     `json:"publish_at"`
+	SalePrice         *money.Amount  `json:"sale_price"`
+	SaleStartsAt      *time.Time     `json:"sale_starts_at"`
+	SaleEndsAt        *time.Time     `json:"sale_ends_at"`
+	Attributes        map[string]any `json:"attributes"`
+}
+
+type Pricing struct {
+	OriginalPrice   money.Amount `json:"original_price"`
+	EffectivePrice  money.Amount `json:"effective_price"`
+	DiscountPercent int          `json:"discount_percent"`
+	Currency        string       `json:"currency"`
+	OnSale          bool         `json:"on_sale"`
+	Source          string       `json:"source,omitempty"`
+	PromotionName   string       `json:"promotion_name,omitempty"`
+	EndsAt          *time.Time   `json:"ends_at,omitempty"`
+	LowestPrice30d  money.Amount `json:"lowest_price_30d"`
+}
+
+type Variant struct {
+	ID                string            `json:"id"`
+	ProductID         string            `json:"product_id"`
+	SKU               string            `json:"sku"`
+	Options           map[string]string `json:"options"`
+	Price             *money.Amount     `json:"price"`
+	EffectivePrice    *money.Amount     `json:"effective_price,omitempty"`
+	ImageURL          string            `json:"image_url"`
+	StockQuantity     *int              `json:"stock_quantity"`
+	InStock           bool              `json:"in_stock"`
+	AvailableQuantity *int              `json:"available_quantity"`
+	Position          int               `json:"position"`
+	CreatedAt         time.Time         `json:"created_at"`
+	UpdatedAt         time.Time         `json:"updated_at"`
+}
+
+type VariantInput struct {
+	SKU      string            `json:"sku"`
+	Options  map[string]string `json:"options"`
+	Price    *money.Amount     `json:"price"`
+	ImageURL string            `json:"image_url"`
+	Position int               `json:"position"`
+}
+
+type Image struct {
+	ID        string    `json:"id"`
+	ProductID string    `json:"product_id"`
+	URL       string    `json:"url"`
+	AltText   string    `json:"alt_text"`
+	Width     *int      `json:"width"`
+	Height    *int      `json:"height"`
+	Position  int       `json:"position"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ImageInput struct {
+	ImageURL string `json:"image_url"`
+	AltText  string `json:"alt_text"`
+	Width    *int   `json:"width"`
+	Height   *int   `json:"height"`
+}
+
+type StockMovement struct {
+	ID            string    `json:"id"`
+	ProductID     string    `json:"product_id"`
+	VariantID     *string   `json:"variant_id"`
+	Delta         int       `json:"delta"`
+	QuantityAfter int       `json:"quantity_after"`
+	Reason        string    `json:"reason"`
+	Note          string    `json:"note"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type StockAdjustmentInput struct {
+	VariantID *string `json:"variant_id"`
+	Delta     int     `json:"delta"`
+	Reason    string  `json:"reason"`
+	Note      string  `json:"note"`
+}
+
+const (
+	RevisionCreate   = "create"
+	RevisionUpdate   = "update"
+	RevisionDelete   = "delete"
+	RevisionRestore  = "restore"
+	RevisionRollback = "rollback"
+)
+
+type Revision struct {
+	ID        string                    `json:"id"`
+	ProductID string                    `json:"product_id"`
+	Action    string                    `json:"action"`
+	Actor     *string                   `json:"actor"`
+	Changes   map[string]RevisionChange `json:"changes"`
+	Snapshot  ProductInput              `json:"snapshot"`
+	CreatedAt time.Time                 `json:"created_at"`
+}
+
+type RevisionChange struct {
+	From any `json:"from"`
+	To   any `json:"to"`
+}
+
+type RevisionPage struct {
+	Items      []Revision `json:"items"`
+	NextCursor *string    `json:"next_cursor"`
+}
+
+const (
+	RelationRelated   = "related"
+	RelationAccessory = "accessory"
+	RelationUpsell    = "upsell"
+	RelationSimilar   = "similar"
+	RelationBought    = "bought_together"
+)
+
+type Relation struct {
+	ProductID string `json:"product_id"`
+	Kind      string `json:"kind"`
+	Position  int    `json:"position"`
+}
+
+type RelationsInput struct {
+	Relations []Relation `json:"relations"`
+}
+
+type RelatedProduct struct {
+	Product
+	Relation string `json:"relation"`
+}
+
+const (
+	LocaleSpanish = "es"
+	LocaleEnglish = "en"
+	defaultLocale = LocaleSpanish
+)
+
+type Translation struct {
+	Locale      string    `json:"locale"`
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	ImageURL    string    `json:"image_url"`
-	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
-type ProductInput struct {
-	Title       string  `json:"title"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price"`
-	ImageURL    string  `json:"image_url"`
+type TranslationInput struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+type MissingTranslation struct {
+	ProductID string   `json:"product_id"`
+	Slug      string   `json:"slug"`
+	Title     string   `json:"title"`
+	Missing   []string `json:"missing"`
+}
+
+type MissingTranslationPage struct {
+	Items      []MissingTranslation `json:"items"`
+	NextCursor *string              `json:"next_cursor"`
+}
+
+type PriceChange struct {
+	ID          string       `json:"id"`
+	ProductID   string       `json:"product_id"`
+	OldPrice    money.Amount `json:"old_price"`
+	OldCurrency string       `json:"old_currency"`
+	NewPrice    money.Amount `json:"new_price"`
+	Currency    string       `json:"currency"`
+	ChangedAt   time.Time    `json:"changed_at"`
+}
+
+type PriceChangePage struct {
+	Items      []PriceChange `json:"items"`
+	NextCursor *string       `json:"next_cursor"`
+}
+
+type StockMovementPage struct {
+	Items      []StockMovement `json:"items"`
+	NextCursor *string         `json:"next_cursor"`
+}
+
+type ListParams struct {
+	Limit         int
+	Sort          string
+	Cursor        string
+	MinPrice      *money.Amount
+	MaxPrice      *money.Amount
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	Category      string
+	Descendants   bool
+	Status        string
+	IncludeHidden bool
+	Attributes    []AttributeFilter
+}
+
+type Page struct {
+	Items      []Product `json:"items"`
+	NextCursor *string   `json:"next_cursor"`
+	Facets     []Facet   `json:"facets,omitempty"`
+}
+
+type SearchResult struct {
+	Product
+	Rank       float32          `json:"rank"`
+	Highlights SearchHighlights `json:"highlights"`
+}
+
+type SearchHighlights struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+}
+
+type SearchPage struct {
+	Items      []SearchResult `json:"items"`
+	NextCursor *string        `json:"next_cursor"`
+}
+
+func (p Product) input() ProductInput {
+	return ProductInput{
+		SKU:               p.SKU,
+		Title:             p.Title,
+		Description:       p.Description,
+		Price:             p.Price,
+		Currency:          p.Currency,
+		ImageURL:          p.ImageURL,
+		LowStockThreshold: p.LowStockThreshold,
+		AllowBackorder:    p.AllowBackorder,
+		Status:            p.Status,
+		PublishAt:         p.PublishAt,
+		SalePrice:         p.SalePrice,
+		SaleStartsAt:      p.SaleStartsAt,
+		SaleEndsAt:        p.SaleEndsAt,
+		Attributes:        p.Attributes,
+	}
+}
+
+func (p *Product) attachVariants(variants []Variant) {
+	if len(variants) == 0 {
+		return
+	}
+	p.Variants = variants
+
+	p.InStock = false
+	for i := range p.Variants {
+		v := &p.Variants[i]
+		v.InStock, v.AvailableQuantity = stockAvailability(v.StockQuantity, p.AllowBackorder)
+		p.InStock = p.InStock || v.InStock
+	}
+}
+
+func stockAvailability(stock *int, allowBackorder bool) (bool, *int) {
+	if stock == nil {
+		return true, nil
+	}
+	available := max(*stock, 0)
+	return allowBackorder || available > 0, &available
+}
+
+func (p *Product) computeInventory() {
+	p.InStock, p.AvailableQuantity = stockAvailability(p.StockQuantity, p.AllowBackorder)
+	p.LowStock = p.AvailableQuantity != nil && *p.AvailableQuantity <= p.LowStockThreshold
+	p.Variants = make([]Variant, 0)
+}
+
+type ImportReport struct {
+	DryRun  bool              `json:"dry_run"`
+	Total   int               `json:"total"`
+	Created int               `json:"created"`
+	Updated int               `json:"updated"`
+	Failed  int               `json:"failed"`
+	Rows    []ImportRowResult `json:"rows"`
+}
+
+type ImportRowResult struct {
+	Line   int     `json:"line"`
+	Action string  `json:"action,omitempty"`
+	ID     string  `json:"id,omitempty"`
+	SKU    *string `json:"sku,omitempty"`
+	Error  string  `json:"error,omitempty"`
+}
+
+const (
+	AttributeText    = "text"
+	AttributeNumber  = "number"
+	AttributeBoolean = "boolean"
+	AttributeEnum    = "enum"
+)
+
+type AttributeDefinition struct {
+	Key           string    `json:"key"`
+	Label         string    `json:"label"`
+	Type          string    `json:"type"`
+	AllowedValues []string  `json:"allowed_values"`
+	Unit          string    `json:"unit"`
+	Filterable    bool      `json:"filterable"`
+	Position      int       `json:"position"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type AttributeDefinitionInput struct {
+	Label         string   `json:"label"`
+	Type          string   `json:"type"`
+	AllowedValues []string `json:"allowed_values"`
+	Unit          string   `json:"unit"`
+	Filterable    bool     `json:"filterable"`
+	Position      int      `json:"position"`
+}
+
+type AttributeFilter struct {
+	Key    string
+	Values []any
+	Min    *float64
+	Max    *float64
+}
+
+type Facet struct {
+	Key    string       `json:"key"`
+	Label  string       `json:"label"`
+	Type   string       `json:"type"`
+	Unit   string       `json:"unit"`
+	Values []FacetValue `json:"values"`
+}
+
+type FacetValue struct {
+	Value any `json:"value"`
+	Count int `json:"count"`
 }
@@ -0,0 +1,213 @@
+package product
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const mergePatchContentType = "application/merge-patch+json"
+
+var (
+	errNullField    = errors.New("field cannot be null")
+	errInvalidField = errors.New("field has an invalid type")
+)
+
+func parseMergePatch(w http.ResponseWriter, r *http.Request) (map[string]json.RawMessage, bool) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != mergePatchContentType {
+		writeError(w, http.StatusUnsupportedMediaType, "content type must be "+mergePatchContentType)
+		return nil, false
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
+
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil || patch == nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return nil, false
+	}
+
+	return patch, true
+}
+
+func applyMergePatch(current Product, patch map[string]json.RawMessage) (ProductInput, error) {
+	input := current.input()
+
+	for _, field := range slices.Sorted(maps.Keys(patch)) {
+		raw := patch[field]
+		var err error
+		switch field {
+		case "title":
+			err = patchString(raw, &input.Title, false)
+			if err == nil {
+				err = validateTitle(input.Title)
+			}
+		case "description":
+			err = patchString(raw, &input.Description, true)
+			if err == nil {
+				err = validateDescription(input.Description)
+			}
+		case "image_url":
+			err = patchString(raw, &input.ImageURL, false)
+			if err == nil {
+				err = validateImageURL(input.ImageURL)
+			}
+		case "price":
+			err = patchValue(raw, &input.Price)
+			if err == nil {
+				err = validatePrice(input.Price)
+			}
+		case "currency":
+			err = patchString(raw, &input.Currency, false)
+			if err == nil {
+				input.Currency = strings.ToUpper(input.Currency)
+				err = validateCurrency(input.Currency)
+			}
+		case "low_stock_threshold":
+			err = patchValue(raw, &input.LowStockThreshold)
+			if err == nil {
+				err = validateLowStockThreshold(input.LowStockThreshold)
+			}
+		case "allow_backorder":
+			err = patchValue(raw, &input.AllowBackorder)
+		case "sku":
+			err = patchNullable(raw, &input.SKU)
+			if err == nil {
+				input.SKU = normalizeSKU(input.SKU)
+				err = validateProductSKU(input.SKU)
+			}
+		case "status":
+			err = patchString(raw, &input.Status, false)
+			if err == nil {
+				input.Status = strings.ToLower(input.Status)
+				if !validStatus(input.Status) {
+					err = errInvalidStatus
+				}
+			}
+		case "publish_at":
+			err = patchNullable(raw, &input.PublishAt)
+		case "sale_price":
+			err = patchNullable(raw, &input.SalePrice)
+		case "sale_starts_at":
+			err = patchNullable(raw, &input.SaleStartsAt)
+		case "sale_ends_at":
+			err = patchNullable(raw, &input.SaleEndsAt)
+		case "attributes":
+			err = patchAttributes(raw, &input.Attributes)
+		default:
+			return ProductInput{}, errors.New("unknown field: " + field)
+		}
+
+		if amountErr, ok := amountError(err); ok {
+			return ProductInput{}, amountErr
+		}
+		if errors.Is(err, errNullField) {
+			return ProductInput{}, errors.New(field + " cannot be null")
+		}
+		if errors.Is(err, errInvalidField) {
+			return ProductInput{}, errors.New(field + " has an invalid type")
+		}
+		if err != nil {
+			return ProductInput{}, err
+		}
+	}
+
+	if hasAnyField(patch, "price", "sale_price", "sale_starts_at", "sale_ends_at") {
+		if err := validateSale(input); err != nil {
+			return ProductInput{}, err
+		}
+	}
+
+	return input, nil
+}
+
+func hasAnyField(patch map[string]json.RawMessage, fields ...string) bool {
+	for _, field := range fields {
+		if _, ok := patch[field]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+func patchString(raw json.RawMessage, target *string, nullable bool) error {
+	if isJSONNull(raw) {
+		if !nullable {
+			return errNullField
+		}
+		*target = ""
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return errInvalidField
+	}
+	*target = strings.TrimSpace(value)
+	return nil
+}
+
+func patchValue[T any](raw json.RawMessage, target *T) error {
+	if isJSONNull(raw) {
+		return errNullField
+	}
+	if err := json.Unmarshal(raw, target); err != nil {
+		if _, ok := amountError(err); ok {
+			return err
+		}
+		return errInvalidField
+	}
+	return nil
+}
+
+func patchNullable[T any](raw json.RawMessage, target **T) error {
+	if isJSONNull(raw) {
+		*target = nil
+		return nil
+	}
+
+	value := new(T)
+	if err := json.Unmarshal(raw, value); err != nil {
+		if _, ok := amountError(err); ok {
+			return err
+		}
+		return errInvalidField
+	}
+	*target = value
+	return nil
+}
+
+func patchAttributes(raw json.RawMessage, target *map[string]any) error {
+	if isJSONNull(raw) {
+		*target = map[string]any{}
+		return nil
+	}
+
+	var changes map[string]any
+	if err := json.Unmarshal(raw, &changes); err != nil {
+		return errInvalidField
+	}
+
+	merged := maps.Clone(*target)
+	if merged == nil {
+		merged = map[string]any{}
+	}
+	for key, value := range changes {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = value
+	}
+	*target = merged
+	return nil
+}
+
+func isJSONNull(raw json.RawMessage) bool {
+	return strings.TrimSpace(string(raw)) == "null"
+}
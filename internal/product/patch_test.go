@@ -0,0 +1,162 @@
+package product
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"store-serverless/internal/money"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	sku := "CRM-001"
+	salePrice := money.FromMinor(4000)
+	saleStartsAt := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+	current := Product{
+		SKU:          &sku,
+		Title:        "Crema solar",
+		Description:  "Protección diaria",
+		Price:        money.FromMinor(5000),
+		Currency:     "PEN",
+		Status:       StatusPublished,
+		SalePrice:    &salePrice,
+		SaleStartsAt: &saleStartsAt,
+		Attributes:   map[string]any{"color": "blanco", "spf": float64(50)},
+	}
+
+	tests := []struct {
+		name    string
+		patch   string
+		wantErr string
+		check   func(t *testing.T, got ProductInput)
+	}{
+		{
+			name:  "empty patch keeps everything",
+			patch: `{}`,
+			check: func(t *testing.T, got ProductInput) {
+				if got.Title != current.Title || got.Price != current.Price || len(got.Attributes) != 2 {
+					t.Fatalf("got %+v, want the current product", got)
+				}
+			},
+		},
+		{
+			name:  "title is trimmed",
+			patch: `{"title": "  Crema FPS 50  "}`,
+			check: func(t *testing.T, got ProductInput) {
+				if got.Title != "Crema FPS 50" {
+					t.Fatalf("title = %q", got.Title)
+				}
+			},
+		},
+		{name: "null title", patch: `{"title": null}`, wantErr: "title cannot be null"},
+		{name: "null price", patch: `{"price": null}`, wantErr: "price cannot be null"},
+		{name: "null allow_backorder", patch: `{"allow_backorder": null}`, wantErr: "allow_backorder cannot be null"},
+		{name: "null currency", patch: `{"currency": null}`, wantErr: "currency cannot be null"},
+		{name: "wrong type", patch: `{"title": 12}`, wantErr: "title has an invalid type"},
+		{name: "price with too many decimals", patch: `{"price": "49.999"}`, wantErr: "amounts must have at most 2 decimals"},
+		{
+			name:  "null description clears it",
+			patch: `{"description": null}`,
+			check: func(t *testing.T, got ProductInput) {
+				if got.Description != "" {
+					t.Fatalf("description = %q, want empty", got.Description)
+				}
+			},
+		},
+		{
+			name:  "null sku clears it",
+			patch: `{"sku": null}`,
+			check: func(t *testing.T, got ProductInput) {
+				if got.SKU != nil {
+					t.Fatalf("sku = %q, want nil", *got.SKU)
+				}
+			},
+		},
+		{
+			name:  "currency is upper-cased",
+			patch: `{"currency": "usd"}`,
+			check: func(t *testing.T, got ProductInput) {
+				if got.Currency != "USD" {
+					t.Fatalf("currency = %q", got.Currency)
+				}
+			},
+		},
+		{
+			name:  "null attribute deletes the key",
+			patch: `{"attributes": {"color": null}}`,
+			check: func(t *testing.T, got ProductInput) {
+				if _, ok := got.Attributes["color"]; ok {
+					t.Fatalf("attributes = %v, want color removed", got.Attributes)
+				}
+				if got.Attributes["spf"] != float64(50) {
+					t.Fatalf("attributes = %v, want spf kept", got.Attributes)
+				}
+			},
+		},
+		{
+			name:  "attribute is merged",
+			patch: `{"attributes": {"size": "M"}}`,
+			check: func(t *testing.T, got ProductInput) {
+				if len(got.Attributes) != 3 || got.Attributes["size"] != "M" {
+					t.Fatalf("attributes = %v", got.Attributes)
+				}
+			},
+		},
+		{
+			name:  "null attributes clears them",
+			patch: `{"attributes": null}`,
+			check: func(t *testing.T, got ProductInput) {
+				if len(got.Attributes) != 0 {
+					t.Fatalf("attributes = %v, want empty", got.Attributes)
+				}
+			},
+		},
+		{
+			name:  "sale_price alone below price",
+			patch: `{"sale_price": "35.00"}`,
+			check: func(t *testing.T, got ProductInput) {
+				if got.SalePrice == nil || *got.SalePrice != money.FromMinor(3500) {
+					t.Fatalf("sale_price = %v", got.SalePrice)
+				}
+			},
+		},
+		{name: "sale_price alone above price", patch: `{"sale_price": "60.00"}`, wantErr: "sale_price must be lower than price"},
+		{name: "price lowered below the current sale", patch: `{"price": "39.00"}`, wantErr: "sale_price must be lower than price"},
+		{name: "sale ends before it starts", patch: `{"sale_ends_at": "2026-06-01T00:00:00Z"}`, wantErr: "sale_ends_at must be after sale_starts_at"},
+		{
+			name:  "null sale_price removes the sale",
+			patch: `{"sale_price": null, "price": "30.00"}`,
+			check: func(t *testing.T, got ProductInput) {
+				if got.SalePrice != nil || got.Price != money.FromMinor(3000) {
+					t.Fatalf("got sale_price %v, price %s", got.SalePrice, got.Price)
+				}
+			},
+		},
+		{name: "unknown field", patch: `{"colour": "rojo"}`, wantErr: "unknown field: colour"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch map[string]json.RawMessage
+			if err := json.Unmarshal([]byte(tt.patch), &patch); err != nil {
+				t.Fatalf("invalid patch %s: %v", tt.patch, err)
+			}
+
+			got, err := applyMergePatch(current, patch)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("applyMergePatch(%s) error = %v, want %q", tt.patch, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyMergePatch(%s) error = %v", tt.patch, err)
+			}
+			tt.check(t, got)
+
+			if len(current.Attributes) != 2 {
+				t.Fatalf("current attributes were modified: %v", current.Attributes)
+			}
+		})
+	}
+}
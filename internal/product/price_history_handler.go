@@ -0,0 +1,35 @@
+package product
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) ListPriceHistory(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	params, ok := parsePageParams(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.repo.ListPriceChanges(r.Context(), id, params.Limit, params.Cursor)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to list price history")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
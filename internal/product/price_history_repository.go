@@ -0,0 +1,286 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"store-serverless/internal/money"
+
+	"github.com/google/uuid"
+)
+
+const (
+	priceHistoryCursorSort = "price_history"
+	lowestPriceWindow      = 30 * 24 * time.Hour
+
+	pricePeriodPrice = "price"
+	pricePeriodSale  = "sale"
+)
+
+func (r *Repository) ListPriceChanges(ctx context.Context, productID string, limit int, rawCursor string) (PriceChangePage, error) {
+	if limit <= 0 || limit > maxListLimit {
+		limit = defaultListLimit
+	}
+
+	var q listQuery
+	q.where("product_id = " + q.arg(productID))
+	if rawCursor != "" {
+		c, err := decodeCursor(rawCursor)
+		if err != nil || c.Sort != priceHistoryCursorSort {
+			return PriceChangePage{}, ErrInvalidCursor
+		}
+		q.where("id < " + q.arg(c.ID) + "::uuid")
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, product_id, old_price, old_currency, new_price, currency, changed_at
+		FROM product_price_changes
+		`+q.whereClause()+`
+		ORDER BY id DESC
+		LIMIT `+q.arg(limit+1), q.args...)
+	if err != nil {
+		return PriceChangePage{}, fmt.Errorf("query price changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := make([]PriceChange, 0, limit)
+	for rows.Next() {
+		var c PriceChange
+		if err := rows.Scan(&c.ID, &c.ProductID, &c.OldPrice, &c.OldCurrency, &c.NewPrice, &c.Currency, &c.ChangedAt); err != nil {
+			return PriceChangePage{}, fmt.Errorf("scan price change: %w", err)
+		}
+		changes = append(changes, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return PriceChangePage{}, fmt.Errorf("iterate price changes: %w", err)
+	}
+
+	page := PriceChangePage{Items: changes}
+	if len(changes) > limit {
+		page.Items = changes[:limit]
+		next := encodeCursor(cursor{Sort: priceHistoryCursorSort, ID: page.Items[limit-1].ID})
+		page.NextCursor = &next
+	}
+
+	return page, nil
+}
+
+type pricePeriod struct {
+	Kind     string
+	Price    money.Amount
+	Currency string
+	StartsAt time.Time
+	EndsAt   *time.Time
+}
+
+type pastPromotion struct {
+	PercentOff int
+	StartsAt   time.Time
+	EndsAt     time.Time
+}
+
+type priceWindow struct {
+	Periods    []pricePeriod
+	Promotions []pastPromotion
+	ExpiredAt  *time.Time
+}
+
+func loadPriceWindows(ctx context.Context, q queryer, productIDs []string, since, now time.Time) (map[string]priceWindow, error) {
+	windows := make(map[string]priceWindow, len(productIDs))
+
+	rows, err := q.QueryContext(ctx, `
+		SELECT product_id, kind, price, currency, starts_at, ends_at
+		FROM product_price_periods
+		WHERE product_id = ANY($1::uuid[]) AND starts_at < $3 AND (ends_at IS NULL OR ends_at > $2)
+		ORDER BY product_id, starts_at
+	`, productIDs, since, now)
+	if err != nil {
+		return nil, fmt.Errorf("query price periods: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productID string
+		var period pricePeriod
+		var endsAt sql.NullTime
+		if err := rows.Scan(&productID, &period.Kind, &period.Price, &period.Currency, &period.StartsAt, &endsAt); err != nil {
+			return nil, fmt.Errorf("scan price period: %w", err)
+		}
+		period.EndsAt = timePointer(endsAt)
+		window := windows[productID]
+		window.Periods = append(window.Periods, period)
+		windows[productID] = window
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate price periods: %w", err)
+	}
+
+	expired, err := q.QueryContext(ctx, `
+		SELECT product_id, MAX(ends_at)
+		FROM product_price_periods
+		WHERE product_id = ANY($1::uuid[]) AND ends_at <= $2
+		GROUP BY product_id
+	`, productIDs, since)
+	if err != nil {
+		return nil, fmt.Errorf("query expired price periods: %w", err)
+	}
+	defer expired.Close()
+
+	for expired.Next() {
+		var productID string
+		var endsAt time.Time
+		if err := expired.Scan(&productID, &endsAt); err != nil {
+			return nil, fmt.Errorf("scan expired price period: %w", err)
+		}
+		expiredAt := endsAt.Add(lowestPriceWindow).UTC()
+		window := windows[productID]
+		window.ExpiredAt = &expiredAt
+		windows[productID] = window
+	}
+	if err := expired.Err(); err != nil {
+		return nil, fmt.Errorf("iterate expired price periods: %w", err)
+	}
+
+	promotions, err := q.QueryContext(ctx, promotionAncestry+`
+		SELECT DISTINCT a.product_id, pr.percent_off, pr.starts_at, pr.ends_at
+		FROM ancestry a
+		JOIN promotions pr ON pr.category_id = a.id
+		WHERE pr.ends_at > $2 AND pr.ends_at <= $3
+	`, productIDs, since, now)
+	if err != nil {
+		return nil, fmt.Errorf("query past promotions: %w", err)
+	}
+	defer promotions.Close()
+
+	for promotions.Next() {
+		var productID string
+		var promo pastPromotion
+		if err := promotions.Scan(&productID, &promo.PercentOff, &promo.StartsAt, &promo.EndsAt); err != nil {
+			return nil, fmt.Errorf("scan past promotion: %w", err)
+		}
+		window := windows[productID]
+		window.Promotions = append(window.Promotions, promo)
+		windows[productID] = window
+	}
+	if err := promotions.Err(); err != nil {
+		return nil, fmt.Errorf("iterate past promotions: %w", err)
+	}
+
+	return windows, nil
+}
+
+func (w priceWindow) lowest(currency string, since, now time.Time) *money.Amount {
+	var lowest *money.Amount
+	consider := func(price money.Amount) {
+		if lowest == nil || price < *lowest {
+			lowest = &price
+		}
+	}
+
+	for _, period := range w.Periods {
+		if period.Currency != currency || (period.Kind == pricePeriodSale && period.activeAt(now)) {
+			continue
+		}
+		consider(period.Price)
+	}
+	for _, promo := range w.Promotions {
+		from := promo.StartsAt
+		if from.Before(since) {
+			from = since
+		}
+		for _, period := range w.Periods {
+			if period.Kind == pricePeriodPrice && period.Currency == currency && period.overlaps(from, promo.EndsAt) {
+				consider(period.Price.ApplyPercentOff(promo.PercentOff))
+			}
+		}
+	}
+
+	return lowest
+}
+
+func (p pricePeriod) activeAt(now time.Time) bool {
+	return !p.StartsAt.After(now) && (p.EndsAt == nil || p.EndsAt.After(now))
+}
+
+func (p pricePeriod) overlaps(from, to time.Time) bool {
+	return p.StartsAt.Before(to) && (p.EndsAt == nil || p.EndsAt.After(from))
+}
+
+func recordPricePeriods(ctx context.Context, tx *sql.Tx, p Product, now time.Time) error {
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE product_price_periods SET ends_at = $2
+		WHERE product_id = $1 AND starts_at < $2 AND (ends_at IS NULL OR ends_at > $2)
+	`, p.ID, now); err != nil {
+		return fmt.Errorf("close price periods: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM product_price_periods WHERE product_id = $1 AND starts_at >= $2
+	`, p.ID, now); err != nil {
+		return fmt.Errorf("drop scheduled price periods: %w", err)
+	}
+
+	if err := insertPricePeriod(ctx, tx, p.ID, pricePeriod{Kind: pricePeriodPrice, Price: p.Price, Currency: p.Currency, StartsAt: now}); err != nil {
+		return err
+	}
+
+	if p.SalePrice == nil {
+		return nil
+	}
+	startsAt := now
+	if p.SaleStartsAt != nil && p.SaleStartsAt.After(now) {
+		startsAt = *p.SaleStartsAt
+	}
+	if p.SaleEndsAt != nil && !p.SaleEndsAt.After(startsAt) {
+		return nil
+	}
+	return insertPricePeriod(ctx, tx, p.ID, pricePeriod{Kind: pricePeriodSale, Price: *p.SalePrice, Currency: p.Currency, StartsAt: startsAt, EndsAt: p.SaleEndsAt})
+}
+
+func insertPricePeriod(ctx context.Context, tx *sql.Tx, productID string, period pricePeriod) error {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("generate uuid v7: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO product_price_periods (id, product_id, kind, price, currency, starts_at, ends_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, id.String(), productID, period.Kind, period.Price, period.Currency, period.StartsAt, period.EndsAt); err != nil {
+		return fmt.Errorf("insert price period: %w", err)
+	}
+
+	return nil
+}
+
+func pricingChanged(before, after Product) bool {
+	return before.Price != after.Price || before.Currency != after.Currency ||
+		!equalAmount(before.SalePrice, after.SalePrice) ||
+		!equalTime(before.SaleStartsAt, after.SaleStartsAt) || !equalTime(before.SaleEndsAt, after.SaleEndsAt)
+}
+
+func equalAmount(a, b *money.Amount) bool {
+	return (a == nil) == (b == nil) && (a == nil || *a == *b)
+}
+
+func equalTime(a, b *time.Time) bool {
+	return (a == nil) == (b == nil) && (a == nil || a.Equal(*b))
+}
+
+func recordPriceChange(ctx context.Context, tx *sql.Tx, productID string, oldPrice money.Amount, oldCurrency string, newPrice money.Amount, currency string, now time.Time) error {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("generate uuid v7: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO product_price_changes (id, product_id, old_price, old_currency, new_price, currency, changed_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, id.String(), productID, oldPrice, oldCurrency, newPrice, currency, now); err != nil {
+		return fmt.Errorf("insert price change: %w", err)
+	}
+
+	return nil
+}
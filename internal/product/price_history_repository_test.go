@@ -0,0 +1,85 @@
+package product
+
+import (
+	"testing"
+	"time"
+
+	"store-serverless/internal/money"
+)
+
+func TestPriceWindowLowest(t *testing.T) {
+	now := time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC)
+	since := now.Add(-lowestPriceWindow)
+	at := func(days int) time.Time { return now.AddDate(0, 0, days) }
+	ptr := func(t time.Time) *time.Time { return &t }
+	base := func(price int64, currency string, from time.Time, to *time.Time) pricePeriod {
+		return pricePeriod{Kind: pricePeriodPrice, Price: money.FromMinor(price), Currency: currency, StartsAt: from, EndsAt: to}
+	}
+	sale := func(price int64, from time.Time, to *time.Time) pricePeriod {
+		return pricePeriod{Kind: pricePeriodSale, Price: money.FromMinor(price), Currency: "PEN", StartsAt: from, EndsAt: to}
+	}
+
+	tests := []struct {
+		name   string
+		window priceWindow
+		want   int64
+	}{
+		{
+			name:   "only the current price",
+			window: priceWindow{Periods: []pricePeriod{base(5000, "PEN", at(-90), nil)}},
+			want:   5000,
+		},
+		{
+			name: "previous price in another currency is ignored",
+			window: priceWindow{Periods: []pricePeriod{
+				base(1000, "USD", at(-20), ptr(at(-10))),
+				base(3000, "PEN", at(-10), nil),
+			}},
+			want: 3000,
+		},
+		{
+			name: "finished sale counts",
+			window: priceWindow{Periods: []pricePeriod{
+				base(5000, "PEN", at(-90), nil),
+				sale(3500, at(-12), ptr(at(-5))),
+			}},
+			want: 3500,
+		},
+		{
+			name: "running sale does not count",
+			window: priceWindow{Periods: []pricePeriod{
+				base(5000, "PEN", at(-90), nil),
+				sale(3500, at(-2), ptr(at(5))),
+			}},
+			want: 5000,
+		},
+		{
+			name: "finished promotion applies to the base price it overlapped",
+			window: priceWindow{
+				Periods: []pricePeriod{
+					base(4000, "PEN", at(-40), ptr(at(-8))),
+					base(6000, "PEN", at(-8), nil),
+				},
+				Promotions: []pastPromotion{{PercentOff: 25, StartsAt: at(-6), EndsAt: at(-3)}},
+			},
+			want: 4000,
+		},
+		{
+			name: "promotion discount below every base price",
+			window: priceWindow{
+				Periods:    []pricePeriod{base(6000, "PEN", at(-90), nil)},
+				Promotions: []pastPromotion{{PercentOff: 50, StartsAt: at(-45), EndsAt: at(-29)}},
+			},
+			want: 3000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.window.lowest("PEN", since, now)
+			if got == nil || *got != money.FromMinor(tt.want) {
+				t.Fatalf("lowest() = %v, want %s", got, money.FromMinor(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package product
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"store-serverless/internal/money"
+)
+
+const (
+	pricingSourceSale      = "sale"
+	pricingSourcePromotion = "promotion"
+)
+
+const promotionAncestry = `
+	WITH RECURSIVE ancestry AS (
+		SELECT pc.product_id, c.id, c.parent_id
+		FROM product_categories pc
+		JOIN categories c ON c.id = pc.category_id
+		WHERE pc.product_id = ANY($1::uuid[])
+		UNION
+		SELECT a.product_id, c.id, c.parent_id
+		FROM ancestry a
+		JOIN categories c ON c.id = a.parent_id
+	)`
+
+type activePromotion struct {
+	Name       string
+	PercentOff int
+	EndsAt     *time.Time
+}
+
+func loadActivePromotions(ctx context.Context, q queryer, productIDs []string, now time.Time) (map[string]activePromotion, error) {
+	rows, err := q.QueryContext(ctx, promotionAncestry+`
+		SELECT DISTINCT ON (a.product_id) a.product_id, pr.name, pr.percent_off, pr.ends_at
+		FROM ancestry a
+		JOIN promotions pr ON pr.category_id = a.id
+		WHERE pr.starts_at <= $2 AND (pr.ends_at IS NULL OR pr.ends_at > $2)
+		ORDER BY a.product_id, pr.percent_off DESC, pr.ends_at ASC NULLS LAST
+	`, productIDs, now)
+	if err != nil {
+		return nil, fmt.Errorf("query active promotions: %w", err)
+	}
+	defer rows.Close()
+
+	promotions := make(map[string]activePromotion)
+	for rows.Next() {
+		var productID string
+		var promo activePromotion
+		var endsAt *time.Time
+		if err := rows.Scan(&productID, &promo.Name, &promo.PercentOff, &endsAt); err != nil {
+			return nil, fmt.Errorf("scan active promotion: %w", err)
+		}
+		if endsAt != nil {
+			utc := endsAt.UTC()
+			promo.EndsAt = &utc
+		}
+		promotions[productID] = promo
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate active promotions: %w", err)
+	}
+
+	return promotions, nil
+}
+
+func (p *Product) computePricing(promo activePromotion, now time.Time) {
+	p.Pricing = Pricing{
+		OriginalPrice:  p.Price,
+		EffectivePrice: p.Price,
+		Currency:       p.Currency,
+	}
+
+	onSale := p.saleActive(now) && *p.SalePrice < p.Price
+	if onSale {
+		p.Pricing.EffectivePrice = *p.SalePrice
+		p.Pricing.Source = pricingSourceSale
+		p.Pricing.PromotionName = ""
+		p.Pricing.EndsAt = p.SaleEndsAt
+	}
+	if promo.PercentOff > 0 {
+		discounted := p.Price.ApplyPercentOff(promo.PercentOff)
+		if discounted < p.Pricing.EffectivePrice {
+			p.Pricing.EffectivePrice = discounted
+			p.Pricing.Source = pricingSourcePromotion
+			p.Pricing.PromotionName = promo.Name
+			p.Pricing.EndsAt = promo.EndsAt
+		}
+	}
+
+	p.Pricing.OnSale = p.Pricing.EffectivePrice < p.Pricing.OriginalPrice
+	p.Pricing.DiscountPercent = discountPercent(p.Pricing.OriginalPrice, p.Pricing.EffectivePrice)
+
+	for i := range p.Variants {
+		v := &p.Variants[i]
+		effective := p.Pricing.EffectivePrice
+		if v.Price != nil {
+			effective = *v.Price
+			if onSale {
+				effective = proportionalPrice(*v.Price, *p.SalePrice, p.Price)
+			}
+			if promo.PercentOff > 0 {
+				effective = min(effective, v.Price.ApplyPercentOff(promo.PercentOff))
+			}
+		}
+		v.EffectivePrice = &effective
+	}
+}
+
+func (p *Product) saleActive(now time.Time) bool {
+	if p.SalePrice == nil {
+		return false
+	}
+	if p.SaleStartsAt != nil && now.Before(*p.SaleStartsAt) {
+		return false
+	}
+	if p.SaleEndsAt != nil && !now.Before(*p.SaleEndsAt) {
+		return false
+	}
+	return true
+}
+
+func proportionalPrice(price, sale, base money.Amount) money.Amount {
+	return money.FromMinor(int64(math.Round(float64(price) * float64(sale) / float64(base))))
+}
+
+func discountPercent(original, effective money.Amount) int {
+	if original <= 0 || effective >= original {
+		return 0
+	}
+	return int(math.Round(float64(original-effective) * 100 / float64(original)))
+}
@@ -0,0 +1,63 @@
+package product
+
+import (
+	"testing"
+	"time"
+
+	"store-serverless/internal/money"
+)
+
+func TestComputePricingVariants(t *testing.T) {
+	now := time.Date(2026, 7, 15, 12, 0, 0, 0, time.UTC)
+	amount := func(minor int64) *money.Amount {
+		value := money.FromMinor(minor)
+		return &value
+	}
+	ended := now.Add(-time.Hour)
+
+	tests := []struct {
+		name         string
+		salePrice    *money.Amount
+		saleEndsAt   *time.Time
+		promo        activePromotion
+		product      int64
+		inherited    int64
+		ownPrice     int64
+		ownEffective int64
+	}{
+		{"no discounts", nil, nil, activePromotion{}, 10000, 10000, 6000, 6000},
+		{"sale applies to variant prices proportionally", amount(8000), nil, activePromotion{}, 8000, 8000, 6000, 4800},
+		{"proportional sale is rounded to cents", amount(6667), nil, activePromotion{}, 6667, 6667, 999, 666},
+		{"ended sale is ignored", amount(8000), &ended, activePromotion{}, 10000, 10000, 6000, 6000},
+		{"promotion applies to variant prices", nil, nil, activePromotion{Name: "Verano", PercentOff: 10}, 9000, 9000, 6000, 5400},
+		{"deeper sale wins over the promotion", amount(7000), nil, activePromotion{Name: "Verano", PercentOff: 10}, 7000, 7000, 6000, 4200},
+		{"deeper promotion wins over the sale", amount(9500), nil, activePromotion{Name: "Verano", PercentOff: 20}, 8000, 8000, 6000, 4800},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := Product{
+				Price:      money.FromMinor(10000),
+				Currency:   "PEN",
+				SalePrice:  tt.salePrice,
+				SaleEndsAt: tt.saleEndsAt,
+				Variants: []Variant{
+					{ID: "inherited"},
+					{ID: "own", Price: amount(tt.ownPrice)},
+				},
+			}
+
+			p.computePricing(tt.promo, now)
+
+			if p.Pricing.EffectivePrice != money.FromMinor(tt.product) {
+				t.Fatalf("product effective price = %s, want %s", p.Pricing.EffectivePrice, money.FromMinor(tt.product))
+			}
+			if got := *p.Variants[0].EffectivePrice; got != money.FromMinor(tt.inherited) {
+				t.Fatalf("inherited variant effective price = %s, want %s", got, money.FromMinor(tt.inherited))
+			}
+			if got := *p.Variants[1].EffectivePrice; got != money.FromMinor(tt.ownEffective) {
+				t.Fatalf("priced variant effective price = %s, want %s", got, money.FromMinor(tt.ownEffective))
+			}
+		})
+	}
+}
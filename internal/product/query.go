@@ -0,0 +1,183 @@
+package product
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+	defaultSort      = "-created_at"
+	searchSort       = "-rank"
+	maxSearchQuery   = 200
+	searchConfig     = "spanish_unaccent"
+)
+
+const productColumns = `p.id, p.slug, p.sku, p.title, p.description, p.price, p.currency, p.image_url,
+	p.status, p.publish_at,
+	p.sale_price, p.sale_starts_at, p.sale_ends_at, p.attributes,
+	p.stock_quantity, p.low_stock_threshold, p.allow_backorder,
+	p.rating_average, p.rating_count, p.rating_updated_at,
+	p.created_at, p.updated_at, p.deleted_at`
+
+type sortOption struct {
+	column string
+	cast   string
+	desc   bool
+	value  func(p Product) string
+}
+
+var sortOptions = map[string]sortOption{
+	"created_at": {column: "p.created_at", cast: "timestamptz", value: createdAtSortValue},
+	"price":      {column: "p.price", cast: "numeric", value: priceSortValue},
+	"title":      {column: "p.title", cast: "text", value: titleSortValue},
+}
+
+func lookupSort(sort string) (sortOption, bool) {
+	desc := strings.HasPrefix(sort, "-")
+	option, ok := sortOptions[strings.TrimPrefix(sort, "-")]
+	if !ok {
+		return sortOption{}, false
+	}
+	option.desc = desc
+	return option, true
+}
+
+var rankSort = sortOption{column: "p.rank", cast: "real", desc: true}
+
+func (s sortOption) orderBy() string {
+	direction := "ASC"
+	if s.desc {
+		direction = "DESC"
+	}
+	return s.column + " " + direction + ", p.id " + direction
+}
+
+func (s sortOption) seek(valuePlaceholder, idPlaceholder string) string {
+	operator := ">"
+	if s.desc {
+		operator = "<"
+	}
+	return "(" + s.column + ", p.id) " + operator + " (" + valuePlaceholder + "::" + s.cast + ", " + idPlaceholder + "::uuid)"
+}
+
+func createdAtSortValue(p Product) string {
+	return p.CreatedAt.UTC().Format(time.RFC3339Nano)
+}
+
+func priceSortValue(p Product) string {
+	return p.Price.String()
+}
+
+func titleSortValue(p Product) string {
+	return p.Title
+}
+
+func rankSortValue(rank float32) string {
+	return strconv.FormatFloat(float64(rank), 'g', -1, 32)
+}
+
+type listQuery struct {
+	conditions []string
+	args       []any
+}
+
+func (q *listQuery) arg(value any) string {
+	q.args = append(q.args, value)
+	return "$" + strconv.Itoa(len(q.args))
+}
+
+func (q *listQuery) where(condition string) {
+	q.conditions = append(q.conditions, condition)
+}
+
+func (q *listQuery) whereClause() string {
+	if len(q.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(q.conditions, " AND ")
+}
+
+const publishedCondition = `p.status = 'published' AND (p.publish_at IS NULL OR p.publish_at <= now())`
+
+func (q *listQuery) applyFilters(params ListParams) {
+	q.where("p.deleted_at IS NULL")
+	if !params.IncludeHidden {
+		q.where(publishedCondition)
+	}
+	if params.Status != "" {
+		q.where("p.status = " + q.arg(params.Status))
+	}
+	if params.MinPrice != nil {
+		q.where("p.price >= " + q.arg(*params.MinPrice))
+	}
+	if params.MaxPrice != nil {
+		q.where("p.price <= " + q.arg(*params.MaxPrice))
+	}
+	if params.CreatedAfter != nil {
+		q.where("p.created_at >= " + q.arg(params.CreatedAfter.UTC()))
+	}
+	if params.CreatedBefore != nil {
+		q.where("p.created_at < " + q.arg(params.CreatedBefore.UTC()))
+	}
+	if params.Category != "" {
+		q.where(categoryCondition(q.arg(params.Category), params.Descendants))
+	}
+	for _, filter := range params.Attributes {
+		q.where(q.attributeCondition(filter))
+	}
+}
+
+func (q *listQuery) attributeCondition(filter AttributeFilter) string {
+	conditions := make([]string, 0, 3)
+	if len(filter.Values) > 0 {
+		matches := make([]string, 0, len(filter.Values))
+		for _, value := range filter.Values {
+			encoded, _ := json.Marshal(map[string]any{filter.Key: value})
+			matches = append(matches, "p.attributes @> "+q.arg(string(encoded))+"::jsonb")
+		}
+		conditions = append(conditions, "("+strings.Join(matches, " OR ")+")")
+	}
+
+	if filter.Min != nil || filter.Max != nil {
+		key := q.arg(filter.Key)
+		number := "CASE WHEN jsonb_typeof(p.attributes -> " + key + ") = 'number' THEN (p.attributes ->> " + key + ")::numeric END"
+		if filter.Min != nil {
+			conditions = append(conditions, number+" >= "+q.arg(*filter.Min)+"::numeric")
+		}
+		if filter.Max != nil {
+			conditions = append(conditions, number+" <= "+q.arg(*filter.Max)+"::numeric")
+		}
+	}
+
+	return strings.Join(conditions, " AND ")
+}
+
+func categoryCondition(slug string, descendants bool) string {
+	if !descendants {
+		return `p.id IN (
+			SELECT pc.product_id
+			FROM product_categories pc
+			JOIN categories c ON c.id = pc.category_id
+			WHERE c.slug = ` + slug + `
+		)`
+	}
+
+	return `p.id IN (
+		WITH RECURSIVE subtree AS (
+			SELECT id FROM categories WHERE slug = ` + slug + `
+			UNION ALL
+			SELECT c.id FROM categories c JOIN subtree ON c.parent_id = subtree.id
+		)
+		SELECT pc.product_id
+		FROM product_categories pc
+		JOIN subtree ON subtree.id = pc.category_id
+	)`
+}
+
+func escapeHTML(column string) string {
+	return `replace(replace(replace(replace(` + column + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;')`
+}
@@ -0,0 +1,128 @@
+package product
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultRelatedLimit = 8
+	maxRelatedLimit     = 20
+)
+
+func (h *Handler) ListRelated(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	limit := defaultRelatedLimit
+	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxRelatedLimit {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxRelatedLimit))
+			return
+		}
+		limit = parsed
+	}
+
+	get := h.repo.GetPublished
+	if isAdmin(r) {
+		get = h.repo.GetByID
+	}
+	if _, err := get(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to get product")
+		return
+	}
+
+	items, err := h.repo.Related(r.Context(), id, limit)
+	if err != nil {
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to list related products")
+		return
+	}
+
+	products := make([]*Product, 0, len(items))
+	for i := range items {
+		products = append(products, &items[i].Product)
+	}
+	if err := h.localize(w, r, products...); err != nil {
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to list related products")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"items": items})
+}
+
+func (h *Handler) ListRelations(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	relations, err := h.repo.ListRelations(r.Context(), id)
+	if err != nil {
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to list relations")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"items": relations})
+}
+
+func (h *Handler) SetRelations(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
+
+	var input RelationsInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+
+	relations, err := normalizeRelations(id, input.Relations)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	relations, err = h.repo.SetRelations(r.Context(), id, relations)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		if errors.Is(err, ErrRelatedProductNotFound) {
+			writeError(w, http.StatusBadRequest, "related product not found")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to set relations")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"items": relations})
+}
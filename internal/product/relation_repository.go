@@ -0,0 +1,236 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const relatedCandidateFactor = 3
+
+func (r *Repository) ListRelations(ctx context.Context, productID string) ([]Relation, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT related_id, kind, position
+		FROM product_relations
+		WHERE product_id = $1
+		ORDER BY position ASC, related_id ASC
+	`, productID)
+	if err != nil {
+		return nil, fmt.Errorf("query relations: %w", err)
+	}
+	defer rows.Close()
+
+	relations := make([]Relation, 0)
+	for rows.Next() {
+		var relation Relation
+		if err := rows.Scan(&relation.ProductID, &relation.Kind, &relation.Position); err != nil {
+			return nil, fmt.Errorf("scan relation: %w", err)
+		}
+		relations = append(relations, relation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate relations: %w", err)
+	}
+
+	return relations, nil
+}
+
+func (r *Repository) SetRelations(ctx context.Context, productID string, relations []Relation) ([]Relation, error) {
+	ids := make([]string, 0, len(relations))
+	kinds := make([]string, 0, len(relations))
+	positions := make([]int, 0, len(relations))
+	for _, relation := range relations {
+		ids = append(ids, relation.ProductID)
+		kinds = append(kinds, relation.Kind)
+		positions = append(positions, relation.Position)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("begin product relations tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var lockedID string
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM products WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, productID).Scan(&lockedID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("lock product: %w", err)
+	}
+
+	var missing bool
+	if err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM unnest($1::uuid[]) AS r(id)
+			LEFT JOIN products p ON p.id = r.id AND p.deleted_at IS NULL
+			WHERE p.id IS NULL
+		)
+	`, ids).Scan(&missing); err != nil {
+		return nil, fmt.Errorf("check related products: %w", err)
+	}
+	if missing {
+		return nil, ErrRelatedProductNotFound
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM product_relations WHERE product_id = $1`, productID); err != nil {
+		return nil, fmt.Errorf("remove product relations: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO product_relations (product_id, related_id, kind, position, created_at)
+		SELECT $1, r.related_id, r.kind, r.position, $5
+		FROM unnest($2::uuid[], $3::text[], $4::int[]) AS r(related_id, kind, position)
+	`, productID, ids, kinds, positions, time.Now().UTC()); err != nil {
+		return nil, fmt.Errorf("insert product relations: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit product relations tx: %w", err)
+	}
+
+	return r.ListRelations(ctx, productID)
+}
+
+func (r *Repository) Related(ctx context.Context, productID string, limit int) ([]RelatedProduct, error) {
+	curated, err := r.curatedRelated(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.hydrateRelated(ctx, curated); err != nil {
+		return nil, err
+	}
+
+	items := appendInStock(make([]RelatedProduct, 0, limit), curated, limit)
+	if len(items) == limit {
+		return items, nil
+	}
+
+	exclude := make([]string, 0, len(curated)+1)
+	exclude = append(exclude, productID)
+	for _, item := range curated {
+		exclude = append(exclude, item.ID)
+	}
+
+	similar, err := r.similarProducts(ctx, productID, exclude, (limit-len(items))*relatedCandidateFactor)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.hydrateRelated(ctx, similar); err != nil {
+		return nil, err
+	}
+
+	return appendInStock(items, similar, limit), nil
+}
+
+func (r *Repository) hydrateRelated(ctx context.Context, items []RelatedProduct) error {
+	pointers := make([]*Product, 0, len(items))
+	for i := range items {
+		pointers = append(pointers, &items[i].Product)
+	}
+	return r.hydrate(ctx, pointers...)
+}
+
+func (r *Repository) curatedRelated(ctx context.Context, productID string) ([]RelatedProduct, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+productColumns+`, rel.kind
+		FROM product_relations rel
+		JOIN products p ON p.id = rel.related_id
+		WHERE rel.product_id = $1 AND p.deleted_at IS NULL AND `+publishedCondition+`
+		ORDER BY rel.position ASC, p.id ASC
+	`, productID)
+	if err != nil {
+		return nil, fmt.Errorf("query curated relations: %w", err)
+	}
+
+	return scanRelatedProducts(rows, "")
+}
+
+func (r *Repository) similarProducts(ctx context.Context, productID string, exclude []string, limit int) ([]RelatedProduct, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH source AS (
+			SELECT attributes FROM products WHERE id = $1
+		),
+		co_purchases AS (
+			SELECT other.product_id, COUNT(DISTINCT other.order_id) AS orders
+			FROM order_items mine
+			JOIN orders o ON o.id = mine.order_id AND o.status NOT IN ('cancelled', 'refunded')
+			JOIN order_items other ON other.order_id = mine.order_id AND other.product_id <> mine.product_id
+			WHERE mine.product_id = $1
+			GROUP BY other.product_id
+		)
+		SELECT `+productColumns+`,
+			CASE WHEN score.co_purchases > 0 THEN '`+RelationBought+`' ELSE '`+RelationSimilar+`' END
+		FROM products p
+		CROSS JOIN source s
+		CROSS JOIN LATERAL (
+			SELECT
+				COALESCE((SELECT cp.orders FROM co_purchases cp WHERE cp.product_id = p.id), 0) AS co_purchases,
+				(
+					SELECT COUNT(*)
+					FROM product_categories pc
+					JOIN product_categories sc ON sc.category_id = pc.category_id AND sc.product_id = $1
+					WHERE pc.product_id = p.id
+				) AS shared_categories,
+				(
+					SELECT COUNT(*)
+					FROM jsonb_each(p.attributes) a
+					WHERE s.attributes @> jsonb_build_object(a.key, a.value)
+				) AS shared_attributes
+		) score
+		WHERE NOT (p.id = ANY($2::uuid[]))
+			AND p.deleted_at IS NULL
+			AND `+publishedCondition+`
+			AND (score.co_purchases > 0 OR score.shared_categories > 0 OR score.shared_attributes > 0)
+		ORDER BY score.co_purchases * 3 + score.shared_categories * 2 + score.shared_attributes DESC, p.id DESC
+		LIMIT $3
+	`, productID, exclude, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query similar products: %w", err)
+	}
+
+	return scanRelatedProducts(rows, "")
+}
+
+func scanRelatedProducts(rows *sql.Rows, relation string) ([]RelatedProduct, error) {
+	defer rows.Close()
+
+	items := make([]RelatedProduct, 0)
+	for rows.Next() {
+		item := RelatedProduct{Relation: relation}
+		var err error
+		if relation == "" {
+			item.Product, err = scanProduct(rows, &item.Relation)
+		} else {
+			item.Product, err = scanProduct(rows)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("scan related product: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate related products: %w", err)
+	}
+
+	return items, nil
+}
+
+func appendInStock(items, candidates []RelatedProduct, limit int) []RelatedProduct {
+	for _, item := range candidates {
+		if len(items) == limit {
+			break
+		}
+		if item.InStock {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+var ErrRelatedProductNotFound = errors.New("related product not found")
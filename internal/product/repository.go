@@ -3,10 +3,13 @@ package product
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type Repository struct {
@@ -17,21 +20,118 @@ func NewRepository(db *sql.DB) *Repository {
 	return &Repository{db: db}
 }
 
-func (r *Repository) List(ctx context.Context) ([]Product, error) {
+func (r *Repository) List(ctx context.Context, params ListParams) (Page, error) {
+	sort, ok := lookupSort(params.Sort)
+	if !ok {
+		return Page{}, ErrInvalidSort
+	}
+	if params.Limit <= 0 || params.Limit > maxListLimit {
+		params.Limit = defaultListLimit
+	}
+
+	var q listQuery
+	q.applyFilters(params)
+	if params.Cursor != "" {
+		c, err := decodeCursor(params.Cursor)
+		if err != nil || c.Sort != params.Sort {
+			return Page{}, ErrInvalidCursor
+		}
+		q.where(sort.seek(q.arg(c.Value), q.arg(c.ID)))
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+productColumns+`
+		FROM products p
+		`+q.whereClause()+`
+		ORDER BY `+sort.orderBy()+`
+		LIMIT `+q.arg(params.Limit+1), q.args...)
+	if err != nil {
+		return Page{}, fmt.Errorf("query products: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]Product, 0, params.Limit)
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return Page{}, fmt.Errorf("scan product: %w", err)
+		}
+		products = append(products, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return Page{}, fmt.Errorf("iterate products: %w", err)
+	}
+
+	page := Page{Items: products}
+	if len(products) > params.Limit {
+		page.Items = products[:params.Limit]
+		last := page.Items[len(page.Items)-1]
+		next := encodeCursor(cursor{Sort: params.Sort, Value: sort.value(last), ID: last.ID})
+		page.NextCursor = &next
+	}
+
+	if err := r.hydrate(ctx, productPointers(page.Items)...); err != nil {
+		return Page{}, err
+	}
+
+	return page, nil
+}
+
+func (r *Repository) GetByID(ctx context.Context, id string) (Product, error) {
+	return r.getProduct(ctx, "p.id = $1 AND p.deleted_at IS NULL", id)
+}
+
+func (r *Repository) GetPublished(ctx context.Context, id string) (Product, error) {
+	return r.getProduct(ctx, "p.id = $1 AND p.deleted_at IS NULL AND "+publishedCondition, id)
+}
+
+func (r *Repository) GetPublishedByIDs(ctx context.Context, ids []string) ([]Product, error) {
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT id, title, description, price, image_url, created_at, updated_at
-		FROM products
-		ORDER BY created_at DESC
-	`)
+		SELECT `+productColumns+`
+		FROM products p
+		WHERE p.id = ANY($1::uuid[]) AND p.deleted_at IS NULL AND `+publishedCondition, ids)
+	if err != nil {
+		return nil, fmt.Errorf("query products by id: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]Product, 0, len(ids))
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan product: %w", err)
+		}
+		products = append(products, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate products: %w", err)
+	}
+
+	if err := r.hydrate(ctx, productPointers(products)...); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func LockPublished(ctx context.Context, tx *sql.Tx, ids []string) ([]Product, error) {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+productColumns+`
+		FROM products p
+		WHERE p.id = ANY($1::uuid[]) AND p.deleted_at IS NULL AND `+publishedCondition+`
+		ORDER BY p.id
+		FOR UPDATE`, ids)
 	if err != nil {
-		return nil, fmt.Errorf("query products: %w", err)
+		return nil, fmt.Errorf("lock products: %w", err)
 	}
 	defer rows.Close()
 
-	products := make([]Product, 0)
+	products := make([]Product, 0, len(ids))
 	for rows.Next() {
-		var p Product
-		if err := rows.Scan(&p.ID, &p.Title, &p.Description, &p.Price, &p.ImageURL, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		p, err := scanProduct(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan product: %w", err)
 		}
 		products = append(products, p)
@@ -41,71 +141,527 @@ func (r *Repository) List(ctx context.Context) ([]Product, error) {
 		return nil, fmt.Errorf("iterate products: %w", err)
 	}
 
+	if err := hydrateWith(ctx, tx, productPointers(products)...); err != nil {
+		return nil, err
+	}
+
 	return products, nil
 }
 
+func (r *Repository) getProduct(ctx context.Context, condition string, args ...any) (Product, error) {
+	p, err := scanProduct(r.db.QueryRowContext(ctx, `
+		SELECT `+productColumns+`
+		FROM products p
+		WHERE `+condition, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Product{}, err
+		}
+		return Product{}, fmt.Errorf("query product by id: %w", err)
+	}
+
+	if err := r.hydrate(ctx, &p); err != nil {
+		return Product{}, err
+	}
+
+	return p, nil
+}
+
+func (r *Repository) Search(ctx context.Context, text string, params ListParams) (SearchPage, error) {
+	if params.Limit <= 0 || params.Limit > maxListLimit {
+		params.Limit = defaultListLimit
+	}
+
+	var q listQuery
+	textArg := q.arg(text)
+	q.where("p.search_vector @@ tsq")
+	q.applyFilters(params)
+	matchWhere := q.whereClause()
+
+	q.conditions = nil
+	if params.Cursor != "" {
+		c, err := decodeCursor(params.Cursor)
+		if err != nil || c.Sort != searchSort {
+			return SearchPage{}, ErrInvalidCursor
+		}
+		q.where(rankSort.seek(q.arg(c.Value), q.arg(c.ID)))
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+productColumns+`, p.rank,
+			ts_headline('`+searchConfig+`', `+escapeHTML("p.title")+`, p.tsq, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+			ts_headline('`+searchConfig+`', `+escapeHTML("p.description")+`, p.tsq, 'StartSel=<mark>, StopSel=</mark>, MaxFragments=2, MinWords=5, MaxWords=20')
+		FROM (
+			SELECT p.*, ts_rank(p.search_vector, tsq) AS rank, tsq
+			FROM products p, websearch_to_tsquery('`+searchConfig+`', `+textArg+`) AS tsq
+			`+matchWhere+`
+		) p
+		`+q.whereClause()+`
+		ORDER BY `+rankSort.orderBy()+`
+		LIMIT `+q.arg(params.Limit+1), q.args...)
+	if err != nil {
+		return SearchPage{}, fmt.Errorf("search products: %w", err)
+	}
+	defer rows.Close()
+
+	results := make([]SearchResult, 0, params.Limit)
+	for rows.Next() {
+		var result SearchResult
+		p, err := scanProduct(rows, &result.Rank, &result.Highlights.Title, &result.Highlights.Description)
+		if err != nil {
+			return SearchPage{}, fmt.Errorf("scan search result: %w", err)
+		}
+		result.Product = p
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return SearchPage{}, fmt.Errorf("iterate search results: %w", err)
+	}
+
+	page := SearchPage{Items: results}
+	if len(results) > params.Limit {
+		page.Items = results[:params.Limit]
+		last := page.Items[len(page.Items)-1]
+		next := encodeCursor(cursor{Sort: searchSort, Value: rankSortValue(last.Rank), ID: last.ID})
+		page.NextCursor = &next
+	}
+
+	items := make([]*Product, 0, len(page.Items))
+	for i := range page.Items {
+		items = append(items, &page.Items[i].Product)
+	}
+	if err := r.hydrate(ctx, items...); err != nil {
+		return SearchPage{}, err
+	}
+
+	return page, nil
+}
+
 func (r *Repository) Create(ctx context.Context, input ProductInput) (Product, error) {
 	id, err := uuid.NewV7()
 	if err != nil {
 		return Product{}, fmt.Errorf("generate uuid v7: %w", err)
 	}
 
+	attributes, err := encodeAttributes(input.Attributes)
+	if err != nil {
+		return Product{}, err
+	}
+
 	now := time.Now().UTC()
-	p := Product{
-		ID:          id.String(),
-		Title:       input.Title,
-		Description: input.Description,
-		Price:       input.Price,
-		ImageURL:    input.ImageURL,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+	base := slugify(input.Title)
+
+	var p Product
+	for attempt := 1; ; attempt++ {
+		p, err = r.insertProduct(ctx, id.String(), base, input, attributes, now)
+		if err == nil {
+			break
+		}
+		if isSlugConflict(err) && attempt < maxSlugAttempts {
+			continue
+		}
+		return Product{}, translateProductError(err, "insert product")
+	}
+
+	if err := r.hydrate(ctx, &p); err != nil {
+		return Product{}, err
 	}
 
-	_, err = r.db.ExecContext(ctx, `
-		INSERT INTO products (id, title, description, price, image_url, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-	`, p.ID, p.Title, p.Description, p.Price, p.ImageURL, p.CreatedAt, p.UpdatedAt)
+	return p, nil
+}
+
+func (r *Repository) insertProduct(ctx context.Context, id, base string, input ProductInput, attributes string, now time.Time) (Product, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return Product{}, fmt.Errorf("insert product: %w", err)
+		return Product{}, fmt.Errorf("begin product insert tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	slug, err := uniqueSlug(ctx, tx, base, id)
+	if err != nil {
+		return Product{}, err
+	}
+
+	p, err := scanProduct(tx.QueryRowContext(ctx, `
+		INSERT INTO products AS p (
+			id, slug, title, description, price, currency, image_url,
+			status, publish_at,
+			sale_price, sale_starts_at, sale_ends_at,
+			low_stock_threshold, allow_backorder, created_at, updated_at, sku, attributes
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $15, $16, $17::jsonb)
+		RETURNING `+productColumns+`
+	`, id, slug, input.Title, input.Description, input.Price, input.Currency, input.ImageURL,
+		input.Status, input.PublishAt,
+		input.SalePrice, input.SaleStartsAt, input.SaleEndsAt,
+		input.LowStockThreshold, input.AllowBackorder, now, input.SKU, attributes))
+	if err != nil {
+		return Product{}, err
+	}
+
+	if err := recordRevision(ctx, tx, p, RevisionCreate, inputChanges(nil, p.input()), now); err != nil {
+		return Product{}, err
+	}
+
+	if err := recordPricePeriods(ctx, tx, p, now); err != nil {
+		return Product{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Product{}, fmt.Errorf("commit product insert tx: %w", err)
 	}
 
 	return p, nil
 }
 
-func (r *Repository) Update(ctx context.Context, id string, input ProductInput) (Product, error) {
+func (r *Repository) Update(ctx context.Context, id string, input ProductInput, expectedUpdatedAt time.Time) (Product, error) {
+	return r.update(ctx, id, input, expectedUpdatedAt, RevisionUpdate)
+}
+
+func (r *Repository) update(ctx context.Context, id string, input ProductInput, expectedUpdatedAt time.Time, action string) (Product, error) {
+	attributes, err := encodeAttributes(input.Attributes)
+	if err != nil {
+		return Product{}, err
+	}
+
+	now := time.Now().UTC()
+
 	var p Product
-	p.UpdatedAt = time.Now().UTC()
+	for attempt := 1; ; attempt++ {
+		p, err = r.updateProduct(ctx, id, input, attributes, expectedUpdatedAt, action, now)
+		if err == nil {
+			break
+		}
+		if isSlugConflict(err) && attempt < maxSlugAttempts {
+			continue
+		}
+		if isSlugConflict(err) {
+			return Product{}, fmt.Errorf("update product: %w", err)
+		}
+		return Product{}, err
+	}
+
+	if err := r.hydrate(ctx, &p); err != nil {
+		return Product{}, err
+	}
+
+	return p, nil
+}
+
+func (r *Repository) updateProduct(ctx context.Context, id string, input ProductInput, attributes string, expectedUpdatedAt time.Time, action string, now time.Time) (Product, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Product{}, fmt.Errorf("begin product update tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var fresh bool
+	current, err := scanProduct(tx.QueryRowContext(ctx, `
+		SELECT `+productColumns+`, ($2::timestamptz IS NULL OR p.updated_at = $2)
+		FROM products p
+		WHERE p.id = $1 AND p.deleted_at IS NULL
+		FOR UPDATE
+	`, id, nullTime(expectedUpdatedAt)), &fresh)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Product{}, err
+		}
+		return Product{}, fmt.Errorf("lock product: %w", err)
+	}
+	if !fresh {
+		return Product{}, ErrPreconditionFailed
+	}
 
-	err := r.db.QueryRowContext(ctx, `
-		UPDATE products
-		SET title = $2, description = $3, price = $4, image_url = $5, updated_at = $6
-		WHERE id = $1
-		RETURNING id, title, description, price, image_url, created_at, updated_at
-	`, id, input.Title, input.Description, input.Price, input.ImageURL, p.UpdatedAt).
-		Scan(&p.ID, &p.Title, &p.Description, &p.Price, &p.ImageURL, &p.CreatedAt, &p.UpdatedAt)
+	slug := current.Slug
+	if input.Title != current.Title {
+		slug, err = uniqueSlug(ctx, tx, slugify(input.Title), id)
+		if err != nil {
+			return Product{}, err
+		}
+		if slug != current.Slug {
+			if err := recordSlugChange(ctx, tx, id, current.Slug, slug, now); err != nil {
+				return Product{}, err
+			}
+		}
+	}
+
+	p, err := scanProduct(tx.QueryRowContext(ctx, `
+		UPDATE products AS p
+		SET title = $2, description = $3, price = $4, currency = $5, image_url = $6,
+			sale_price = $7, sale_starts_at = $8, sale_ends_at = $9,
+			low_stock_threshold = $10, allow_backorder = $11, updated_at = $12,
+			status = CASE WHEN $13::text = '' THEN p.status ELSE $13::text END,
+			publish_at = CASE WHEN $13::text = '' THEN p.publish_at ELSE $14::timestamptz END,
+			slug = $15, sku = $16, attributes = $17::jsonb
+		WHERE p.id = $1
+		RETURNING `+productColumns+`
+	`, id, input.Title, input.Description, input.Price, input.Currency, input.ImageURL,
+		input.SalePrice, input.SaleStartsAt, input.SaleEndsAt,
+		input.LowStockThreshold, input.AllowBackorder, now,
+		input.Status, input.PublishAt, slug, input.SKU, attributes))
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if isSlugConflict(err) {
 			return Product{}, err
 		}
-		return Product{}, fmt.Errorf("update product: %w", err)
+		return Product{}, translateProductError(err, "update product")
+	}
+
+	if p.Price != current.Price || p.Currency != current.Currency {
+		if err := recordPriceChange(ctx, tx, id, current.Price, current.Currency, p.Price, p.Currency, now); err != nil {
+			return Product{}, err
+		}
+	}
+	if pricingChanged(current, p) {
+		if err := recordPricePeriods(ctx, tx, p, now); err != nil {
+			return Product{}, err
+		}
+	}
+
+	before := current.input()
+	if changes := inputChanges(&before, p.input()); len(changes) > 0 {
+		if err := recordRevision(ctx, tx, p, action, changes, now); err != nil {
+			return Product{}, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Product{}, fmt.Errorf("commit product update tx: %w", err)
 	}
 
 	return p, nil
 }
 
-func (r *Repository) Delete(ctx context.Context, id string) error {
-	res, err := r.db.ExecContext(ctx, `DELETE FROM products WHERE id = $1`, id)
+func (r *Repository) Delete(ctx context.Context, id string, expectedUpdatedAt time.Time) error {
+	now := time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("delete product: %w", err)
+		return fmt.Errorf("begin product delete tx: %w", err)
 	}
+	defer tx.Rollback()
 
-	affected, err := res.RowsAffected()
+	p, err := scanProduct(tx.QueryRowContext(ctx, `
+		UPDATE products AS p
+		SET deleted_at = $3, updated_at = $3
+		WHERE p.id = $1 AND p.deleted_at IS NULL AND ($2::timestamptz IS NULL OR p.updated_at = $2)
+		RETURNING `+productColumns+`
+	`, id, nullTime(expectedUpdatedAt), now))
 	if err != nil {
-		return fmt.Errorf("rows affected: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return r.missingOrStale(ctx, id, expectedUpdatedAt)
+		}
+		return fmt.Errorf("delete product: %w", err)
 	}
-	if affected == 0 {
+
+	changes := map[string]RevisionChange{"deleted_at": {From: nil, To: now}}
+	if err := recordRevision(ctx, tx, p, RevisionDelete, changes, now); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit product delete tx: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) missingOrStale(ctx context.Context, id string, expectedUpdatedAt time.Time) error {
+	if expectedUpdatedAt.IsZero() {
 		return sql.ErrNoRows
 	}
 
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM products WHERE id = $1 AND deleted_at IS NULL)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("check product exists: %w", err)
+	}
+	if exists {
+		return ErrPreconditionFailed
+	}
+
+	return sql.ErrNoRows
+}
+
+func encodeAttributes(attributes map[string]any) (string, error) {
+	if attributes == nil {
+		return "{}", nil
+	}
+	encoded, err := json.Marshal(attributes)
+	if err != nil {
+		return "", fmt.Errorf("encode attributes: %w", err)
+	}
+	return string(encoded), nil
+}
+
+func translateProductError(err error, action string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "products_sku_unique" {
+		return ErrDuplicateSKU
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
+
+func nullTime(value time.Time) sql.NullTime {
+	return sql.NullTime{Time: value, Valid: !value.IsZero()}
+}
+
+func timePointer(value sql.NullTime) *time.Time {
+	if !value.Valid {
+		return nil
+	}
+	t := value.Time.UTC()
+	return &t
+}
+
+func (r *Repository) hydrate(ctx context.Context, products ...*Product) error {
+	return hydrateWith(ctx, r.db, products...)
+}
+
+func hydrateWith(ctx context.Context, q queryer, products ...*Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+
+	variants, err := loadVariants(ctx, q, ids)
+	if err != nil {
+		return err
+	}
+
+	images, err := loadImages(ctx, q, ids)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	promotions, err := loadActivePromotions(ctx, q, ids, now)
+	if err != nil {
+		return err
+	}
+
+	since := now.Add(-lowestPriceWindow)
+	windows, err := loadPriceWindows(ctx, q, ids, since, now)
+	if err != nil {
+		return err
+	}
+
+	catalogChangedAt, err := loadCatalogChangedAt(ctx, q, now)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range products {
+		p.attachVariants(variants[p.ID])
+		p.Images = images[p.ID]
+		if p.Images == nil {
+			p.Images = []Image{}
+		}
+		p.computePricing(promotions[p.ID], now)
+		p.Pricing.LowestPrice30d = p.Price
+		window := windows[p.ID]
+		if lowest := window.lowest(p.Currency, since, now); lowest != nil && *lowest < p.Price {
+			p.Pricing.LowestPrice30d = *lowest
+		}
+		p.computeChangedAt(now, catalogChangedAt, window.ExpiredAt)
+	}
+
 	return nil
 }
+
+func loadCatalogChangedAt(ctx context.Context, q queryer, now time.Time) (time.Time, error) {
+	var changedAt, promotionExpiredAt sql.NullTime
+	if err := q.QueryRowContext(ctx, `
+		SELECT GREATEST(
+			(SELECT MAX(changed_at) FROM catalog_changes),
+			(SELECT MAX(updated_at) FROM promotions),
+			(SELECT MAX(starts_at) FROM promotions WHERE starts_at <= $1),
+			(SELECT MAX(ends_at) FROM promotions WHERE ends_at <= $1)
+		), (SELECT MAX(ends_at) FROM promotions WHERE ends_at <= $2)
+	`, now, now.Add(-lowestPriceWindow)).Scan(&changedAt, &promotionExpiredAt); err != nil {
+		return time.Time{}, fmt.Errorf("query catalog changes: %w", err)
+	}
+	if expiredAt := promotionExpiredAt.Time.Add(lowestPriceWindow); promotionExpiredAt.Valid && expiredAt.After(changedAt.Time) {
+		return expiredAt, nil
+	}
+	return changedAt.Time, nil
+}
+
+func (r *Repository) ListModifiedAt(ctx context.Context) (time.Time, error) {
+	now := time.Now().UTC()
+	catalogChangedAt, err := loadCatalogChangedAt(ctx, r.db, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var changedAt sql.NullTime
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT GREATEST(
+			(SELECT MAX(updated_at) FROM products),
+			(SELECT MAX(rating_updated_at) FROM products),
+			(SELECT MAX(publish_at) FROM products WHERE publish_at <= $1),
+			(SELECT MAX(updated_at) FROM attribute_definitions)
+		)
+	`, now).Scan(&changedAt); err != nil {
+		return time.Time{}, fmt.Errorf("query catalog modified at: %w", err)
+	}
+	if catalogChangedAt.After(changedAt.Time) {
+		return catalogChangedAt, nil
+	}
+	return changedAt.Time, nil
+}
+
+func productPointers(products []Product) []*Product {
+	pointers := make([]*Product, 0, len(products))
+	for i := range products {
+		pointers = append(pointers, &products[i])
+	}
+	return pointers
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanProduct(row rowScanner, extra ...any) (Product, error) {
+	var p Product
+	var stock sql.NullInt64
+	var publishAt, saleStartsAt, saleEndsAt, ratingUpdatedAt, deletedAt sql.NullTime
+	var attributes []byte
+	dest := []any{
+		&p.ID, &p.Slug, &p.SKU, &p.Title, &p.Description, &p.Price, &p.Currency, &p.ImageURL,
+		&p.Status, &publishAt,
+		&p.SalePrice, &saleStartsAt, &saleEndsAt, &attributes,
+		&stock, &p.LowStockThreshold, &p.AllowBackorder,
+		&p.RatingAverage, &p.RatingCount, &ratingUpdatedAt,
+		&p.CreatedAt, &p.UpdatedAt, &deletedAt,
+	}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return Product{}, err
+	}
+	if stock.Valid {
+		quantity := int(stock.Int64)
+		p.StockQuantity = &quantity
+	}
+	p.PublishAt = timePointer(publishAt)
+	p.SaleStartsAt = timePointer(saleStartsAt)
+	p.SaleEndsAt = timePointer(saleEndsAt)
+	p.DeletedAt = timePointer(deletedAt)
+	p.ratingUpdatedAt = ratingUpdatedAt.Time
+	if err := json.Unmarshal(attributes, &p.Attributes); err != nil {
+		return Product{}, fmt.Errorf("decode attributes: %w", err)
+	}
+	if p.Attributes == nil {
+		p.Attributes = map[string]any{}
+	}
+	p.Locale = defaultLocale
+	p.computeInventory()
+	return p, nil
+}
+
+var ErrInvalidSort = errors.New("invalid sort")
+
+var ErrPreconditionFailed = errors.New("precondition failed")
@@ -0,0 +1,90 @@
+package product
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	params, ok := parsePageParams(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.repo.ListRevisions(r.Context(), id, params.Limit, params.Cursor)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to list product history")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (h *Handler) RollbackProduct(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	revisionID := r.PathValue("revisionID")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+	if _, err := uuid.Parse(revisionID); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid revision id")
+		return
+	}
+
+	rev, err := h.repo.GetRevision(r.Context(), id, revisionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "revision not found")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to get revision")
+		return
+	}
+	if !h.checkAttributes(w, r, rev.Snapshot.Attributes) {
+		return
+	}
+
+	expectedUpdatedAt, ok := h.checkIfMatch(w, r, id)
+	if !ok {
+		return
+	}
+
+	p, err := h.repo.Rollback(r.Context(), id, rev, expectedUpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		if errors.Is(err, ErrPreconditionFailed) {
+			writeError(w, http.StatusPreconditionFailed, "product has been modified")
+			return
+		}
+		if errors.Is(err, ErrDuplicateSKU) {
+			writeError(w, http.StatusConflict, "sku already exists")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to roll back product")
+		return
+	}
+
+	w.Header().Set("ETag", productETag(p))
+	writeJSON(w, http.StatusOK, p)
+}
@@ -0,0 +1,163 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"store-serverless/internal/auth"
+
+	"github.com/google/uuid"
+)
+
+const revisionCursorSort = "revisions"
+
+const revisionColumns = `id, product_id, action, actor, changes, snapshot, created_at`
+
+func (r *Repository) ListRevisions(ctx context.Context, productID string, limit int, rawCursor string) (RevisionPage, error) {
+	if limit <= 0 || limit > maxListLimit {
+		limit = defaultListLimit
+	}
+
+	var q listQuery
+	q.where("product_id = " + q.arg(productID))
+	if rawCursor != "" {
+		c, err := decodeCursor(rawCursor)
+		if err != nil || c.Sort != revisionCursorSort {
+			return RevisionPage{}, ErrInvalidCursor
+		}
+		q.where("id < " + q.arg(c.ID) + "::uuid")
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+revisionColumns+`
+		FROM product_revisions
+		`+q.whereClause()+`
+		ORDER BY id DESC
+		LIMIT `+q.arg(limit+1), q.args...)
+	if err != nil {
+		return RevisionPage{}, fmt.Errorf("query revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := make([]Revision, 0, limit)
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return RevisionPage{}, fmt.Errorf("scan revision: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+
+	if err := rows.Err(); err != nil {
+		return RevisionPage{}, fmt.Errorf("iterate revisions: %w", err)
+	}
+
+	page := RevisionPage{Items: revisions}
+	if len(revisions) > limit {
+		page.Items = revisions[:limit]
+		next := encodeCursor(cursor{Sort: revisionCursorSort, ID: page.Items[limit-1].ID})
+		page.NextCursor = &next
+	}
+
+	return page, nil
+}
+
+func (r *Repository) GetRevision(ctx context.Context, productID, revisionID string) (Revision, error) {
+	rev, err := scanRevision(r.db.QueryRowContext(ctx, `
+		SELECT `+revisionColumns+`
+		FROM product_revisions
+		WHERE product_id = $1 AND id = $2
+	`, productID, revisionID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Revision{}, err
+		}
+		return Revision{}, fmt.Errorf("query revision: %w", err)
+	}
+
+	return rev, nil
+}
+
+func (r *Repository) Rollback(ctx context.Context, id string, rev Revision, expectedUpdatedAt time.Time) (Product, error) {
+	return r.update(ctx, id, rev.Snapshot, expectedUpdatedAt, RevisionRollback)
+}
+
+func recordRevision(ctx context.Context, tx *sql.Tx, p Product, action string, changes map[string]RevisionChange, now time.Time) error {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("generate uuid v7: %w", err)
+	}
+
+	encodedChanges, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("encode revision changes: %w", err)
+	}
+	snapshot, err := json.Marshal(p.input())
+	if err != nil {
+		return fmt.Errorf("encode revision snapshot: %w", err)
+	}
+
+	var actor *string
+	if subject, ok := auth.Subject(ctx); ok && subject != "" {
+		actor = &subject
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO product_revisions (id, product_id, action, actor, changes, snapshot, created_at)
+		VALUES ($1, $2, $3, $4, $5::jsonb, $6::jsonb, $7)
+	`, id.String(), p.ID, action, actor, string(encodedChanges), string(snapshot), now); err != nil {
+		return fmt.Errorf("insert revision: %w", err)
+	}
+
+	return nil
+}
+
+func inputChanges(before *ProductInput, after ProductInput) map[string]RevisionChange {
+	previous := map[string]any{}
+	if before != nil {
+		previous = inputFields(*before)
+	}
+
+	changes := make(map[string]RevisionChange)
+	for field, value := range inputFields(after) {
+		old, ok := previous[field]
+		if before == nil && value == nil {
+			continue
+		}
+		if !ok || !reflect.DeepEqual(old, value) {
+			changes[field] = RevisionChange{From: old, To: value}
+		}
+	}
+	return changes
+}
+
+func inputFields(input ProductInput) map[string]any {
+	encoded, _ := json.Marshal(input)
+	fields := map[string]any{}
+	_ = json.Unmarshal(encoded, &fields)
+	return fields
+}
+
+func scanRevision(row rowScanner) (Revision, error) {
+	var rev Revision
+	var actor sql.NullString
+	var changes, snapshot []byte
+	if err := row.Scan(&rev.ID, &rev.ProductID, &rev.Action, &actor, &changes, &snapshot, &rev.CreatedAt); err != nil {
+		return Revision{}, err
+	}
+	if actor.Valid {
+		rev.Actor = &actor.String
+	}
+	if err := json.Unmarshal(changes, &rev.Changes); err != nil {
+		return Revision{}, fmt.Errorf("decode revision changes: %w", err)
+	}
+	if err := json.Unmarshal(snapshot, &rev.Snapshot); err != nil {
+		return Revision{}, fmt.Errorf("decode revision snapshot: %w", err)
+	}
+	return rev, nil
+}
@@ -0,0 +1,151 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	maxSlugLength   = 80
+	fallbackSlug    = "producto"
+	slugConstraint  = "products_slug_unique"
+	maxSlugAttempts = 3
+)
+
+var slugRegex = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+var reservedSlugs = map[string]struct{}{
+	"export": {},
+	"import": {},
+	"search": {},
+	"trash":  {},
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range norm.NFD.String(strings.ToLower(title)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case r == 'ß':
+			b.WriteString("ss")
+			dash = false
+		case r == 'ø':
+			b.WriteByte('o')
+			dash = false
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			b.WriteRune(r)
+			dash = false
+		default:
+			if !dash && b.Len() > 0 {
+				b.WriteByte('-')
+				dash = true
+			}
+		}
+	}
+
+	slug := strings.Trim(b.String(), "-")
+	if len(slug) > maxSlugLength {
+		slug = strings.TrimRight(slug[:maxSlugLength], "-")
+	}
+	if slug == "" {
+		return fallbackSlug
+	}
+	return slug
+}
+
+func uniqueSlug(ctx context.Context, q queryer, base, productID string) (string, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT slug FROM products
+		WHERE (slug = $1 OR slug LIKE $1 || '-%') AND id <> $2
+		UNION
+		SELECT slug FROM product_slug_history
+		WHERE (slug = $1 OR slug LIKE $1 || '-%') AND product_id <> $2
+	`, base, productID)
+	if err != nil {
+		return "", fmt.Errorf("query taken slugs: %w", err)
+	}
+	defer rows.Close()
+
+	taken := make(map[string]struct{})
+	for slug := range reservedSlugs {
+		taken[slug] = struct{}{}
+	}
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return "", fmt.Errorf("scan taken slug: %w", err)
+		}
+		taken[slug] = struct{}{}
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("iterate taken slugs: %w", err)
+	}
+
+	candidate := base
+	for n := 2; ; n++ {
+		if _, ok := taken[candidate]; !ok {
+			return candidate, nil
+		}
+		candidate = base + "-" + strconv.Itoa(n)
+	}
+}
+
+func recordSlugChange(ctx context.Context, tx *sql.Tx, productID, oldSlug, newSlug string, now time.Time) error {
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO product_slug_history (slug, product_id, created_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (slug) DO UPDATE SET product_id = EXCLUDED.product_id, created_at = EXCLUDED.created_at
+	`, oldSlug, productID, now); err != nil {
+		return fmt.Errorf("insert slug history: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM product_slug_history WHERE slug = $1 AND product_id = $2
+	`, newSlug, productID); err != nil {
+		return fmt.Errorf("reclaim slug history: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) ResolveSlug(ctx context.Context, slug string) (string, error) {
+	var id string
+	err := r.db.QueryRowContext(ctx, `
+		SELECT id FROM products WHERE slug = $1 AND deleted_at IS NULL
+		UNION ALL
+		SELECT h.product_id FROM product_slug_history h
+		JOIN products p ON p.id = h.product_id
+		WHERE h.slug = $1 AND p.deleted_at IS NULL
+		LIMIT 1
+	`, slug).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", err
+		}
+		return "", fmt.Errorf("resolve product slug: %w", err)
+	}
+
+	return id, nil
+}
+
+func isSlugConflict(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == slugConstraint
+}
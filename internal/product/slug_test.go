@@ -0,0 +1,42 @@
+package product
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{"plain title", "Crema Solar FPS 50", "crema-solar-fps-50"},
+		{"accents", "Café con leche de Ñuñoa", "cafe-con-leche-de-nunoa"},
+		{"upper-case accents", "ÁRBOL ÉLITE", "arbol-elite"},
+		{"sharp s", "Straße", "strasse"},
+		{"slashed o", "Smørrebrød", "smorrebrod"},
+		{"punctuation runs", "¡Oferta!!! -- 2x1 ... ya", "oferta-2x1-ya"},
+		{"symbols between words", "C++ & Go", "c-go"},
+		{"leading and trailing punctuation", "  ...Jabón líquido!  ", "jabon-liquido"},
+		{"exactly the limit", strings.Repeat("a", 80), strings.Repeat("a", 80)},
+		{"long word is cut", strings.Repeat("a", 100), strings.Repeat("a", 80)},
+		{"cut on a dash boundary", strings.Repeat("abcd ", 20), strings.TrimSuffix(strings.Repeat("abcd-", 16), "-")},
+		{"cut after a dash", strings.Repeat("a", 79) + " bcd", strings.Repeat("a", 79)},
+		{"empty title", "", fallbackSlug},
+		{"only punctuation", "¡¿...?!", fallbackSlug},
+		{"no latin letters", "日本語", fallbackSlug},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slugify(tt.title)
+			if got != tt.want {
+				t.Fatalf("slugify(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+			if !slugRegex.MatchString(got) {
+				t.Fatalf("slugify(%q) = %q, which is not a valid slug", tt.title, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package product
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) AdjustStock(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
+
+	var input StockAdjustmentInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+
+	if input.VariantID != nil {
+		parsed, err := uuid.Parse(strings.TrimSpace(*input.VariantID))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid variant id")
+			return
+		}
+		variantID := parsed.String()
+		input.VariantID = &variantID
+	}
+
+	input.Reason = strings.TrimSpace(strings.ToLower(input.Reason))
+	input.Note = strings.TrimSpace(input.Note)
+	if err := validateStockAdjustment(input); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	p, movement, err := h.repo.AdjustStock(r.Context(), id, input)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "product or variant not found")
+			return
+		}
+		if errors.Is(err, ErrInsufficientStock) {
+			writeError(w, http.StatusConflict, "insufficient stock")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to adjust stock")
+		return
+	}
+
+	w.Header().Set("ETag", productETag(p))
+	writeJSON(w, http.StatusOK, map[string]any{
+		"product":  p,
+		"movement": movement,
+	})
+}
+
+func (h *Handler) ListStockMovements(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	params, ok := parsePageParams(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.repo.ListStockMovements(r.Context(), id, params.Limit, params.Cursor)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to list stock movements")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
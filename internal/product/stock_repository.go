@@ -0,0 +1,217 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const stockMovementCursorSort = "stock_movements"
+
+func (r *Repository) AdjustStock(ctx context.Context, productID string, input StockAdjustmentInput) (Product, StockMovement, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return Product{}, StockMovement{}, fmt.Errorf("generate uuid v7: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Product{}, StockMovement{}, fmt.Errorf("begin stock adjustment tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	quantityAfter, err := applyStockDelta(ctx, tx, productID, input.VariantID, input.Delta, now)
+	if err != nil {
+		return Product{}, StockMovement{}, err
+	}
+
+	movement := StockMovement{
+		ID:            id.String(),
+		ProductID:     productID,
+		VariantID:     input.VariantID,
+		Delta:         input.Delta,
+		QuantityAfter: quantityAfter,
+		Reason:        input.Reason,
+		Note:          input.Note,
+	}
+	if err := insertStockMovement(ctx, tx, &movement, now); err != nil {
+		return Product{}, StockMovement{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Product{}, StockMovement{}, fmt.Errorf("commit stock adjustment tx: %w", err)
+	}
+
+	p, err := r.GetByID(ctx, productID)
+	if err != nil {
+		return Product{}, StockMovement{}, err
+	}
+
+	return p, movement, nil
+}
+
+func (r *Repository) ListStockMovements(ctx context.Context, productID string, limit int, rawCursor string) (StockMovementPage, error) {
+	if limit <= 0 || limit > maxListLimit {
+		limit = defaultListLimit
+	}
+
+	var q listQuery
+	q.where("product_id = " + q.arg(productID))
+	if rawCursor != "" {
+		c, err := decodeCursor(rawCursor)
+		if err != nil || c.Sort != stockMovementCursorSort {
+			return StockMovementPage{}, ErrInvalidCursor
+		}
+		q.where("id < " + q.arg(c.ID) + "::uuid")
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, product_id, variant_id, delta, quantity_after, reason, note, created_at
+		FROM stock_movements
+		`+q.whereClause()+`
+		ORDER BY id DESC
+		LIMIT `+q.arg(limit+1), q.args...)
+	if err != nil {
+		return StockMovementPage{}, fmt.Errorf("query stock movements: %w", err)
+	}
+	defer rows.Close()
+
+	movements := make([]StockMovement, 0, limit)
+	for rows.Next() {
+		var m StockMovement
+		var variantID sql.NullString
+		if err := rows.Scan(&m.ID, &m.ProductID, &variantID, &m.Delta, &m.QuantityAfter, &m.Reason, &m.Note, &m.CreatedAt); err != nil {
+			return StockMovementPage{}, fmt.Errorf("scan stock movement: %w", err)
+		}
+		if variantID.Valid {
+			m.VariantID = &variantID.String
+		}
+		movements = append(movements, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return StockMovementPage{}, fmt.Errorf("iterate stock movements: %w", err)
+	}
+
+	page := StockMovementPage{Items: movements}
+	if len(movements) > limit {
+		page.Items = movements[:limit]
+		next := encodeCursor(cursor{Sort: stockMovementCursorSort, ID: page.Items[limit-1].ID})
+		page.NextCursor = &next
+	}
+
+	return page, nil
+}
+
+func MoveStock(ctx context.Context, tx *sql.Tx, productID string, variantID *string, delta int, reason, note string) error {
+	var tracked bool
+	var err error
+	if variantID == nil {
+		err = tx.QueryRowContext(ctx, `
+			SELECT stock_quantity IS NOT NULL
+			FROM products
+			WHERE id = $1 AND deleted_at IS NULL
+			FOR UPDATE
+		`, productID).Scan(&tracked)
+	} else {
+		err = tx.QueryRowContext(ctx, `
+			SELECT v.stock_quantity IS NOT NULL
+			FROM product_variants v
+			JOIN products p ON p.id = v.product_id
+			WHERE v.product_id = $1 AND v.id = $2 AND p.deleted_at IS NULL
+			FOR UPDATE OF v
+		`, productID, *variantID).Scan(&tracked)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		return fmt.Errorf("lock stock: %w", err)
+	}
+	if !tracked {
+		return nil
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("generate uuid v7: %w", err)
+	}
+
+	now := time.Now().UTC()
+	quantityAfter, err := applyStockDelta(ctx, tx, productID, variantID, delta, now)
+	if err != nil {
+		return err
+	}
+
+	return insertStockMovement(ctx, tx, &StockMovement{
+		ID:            id.String(),
+		ProductID:     productID,
+		VariantID:     variantID,
+		Delta:         delta,
+		QuantityAfter: quantityAfter,
+		Reason:        reason,
+		Note:          note,
+	}, now)
+}
+
+func insertStockMovement(ctx context.Context, tx *sql.Tx, movement *StockMovement, now time.Time) error {
+	err := tx.QueryRowContext(ctx, `
+		INSERT INTO stock_movements (id, product_id, variant_id, delta, quantity_after, reason, note, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`, movement.ID, movement.ProductID, movement.VariantID, movement.Delta, movement.QuantityAfter, movement.Reason, movement.Note, now).
+		Scan(&movement.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert stock movement: %w", err)
+	}
+	return nil
+}
+
+func applyStockDelta(ctx context.Context, tx *sql.Tx, productID string, variantID *string, delta int, now time.Time) (int, error) {
+	var quantityAfter int
+	var allowBackorder bool
+
+	var err error
+	if variantID == nil {
+		err = tx.QueryRowContext(ctx, `
+			UPDATE products
+			SET stock_quantity = COALESCE(stock_quantity, 0) + $2, updated_at = $3
+			WHERE id = $1 AND deleted_at IS NULL
+			RETURNING stock_quantity, allow_backorder
+		`, productID, delta, now).Scan(&quantityAfter, &allowBackorder)
+	} else {
+		err = tx.QueryRowContext(ctx, `
+			WITH product AS (
+				UPDATE products
+				SET updated_at = $4
+				WHERE id = $1 AND deleted_at IS NULL
+				RETURNING id, allow_backorder
+			)
+			UPDATE product_variants v
+			SET stock_quantity = COALESCE(v.stock_quantity, 0) + $3, updated_at = $4
+			FROM product
+			WHERE v.product_id = product.id AND v.id = $2
+			RETURNING v.stock_quantity, product.allow_backorder
+		`, productID, *variantID, delta, now).Scan(&quantityAfter, &allowBackorder)
+	}
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, err
+		}
+		return 0, fmt.Errorf("update stock: %w", err)
+	}
+
+	if delta < 0 && quantityAfter < 0 && !allowBackorder {
+		return 0, ErrInsufficientStock
+	}
+
+	return quantityAfter, nil
+}
+
+var ErrInsufficientStock = errors.New("insufficient stock")
@@ -0,0 +1,196 @@
+package product
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+	"golang.org/x/text/language"
+)
+
+var supportedLocales = []string{LocaleSpanish, LocaleEnglish}
+
+var localeMatcher = language.NewMatcher([]language.Tag{language.Spanish, language.English})
+
+func (h *Handler) ListTranslations(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	if _, err := h.repo.GetByID(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to get product")
+		return
+	}
+
+	translations, err := h.repo.ListTranslations(r.Context(), id)
+	if err != nil {
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to list translations")
+		return
+	}
+
+	missing := make([]string, 0)
+	for _, locale := range supportedLocales {
+		if locale == defaultLocale {
+			continue
+		}
+		found := slices.ContainsFunc(translations, func(t Translation) bool { return t.Locale == locale })
+		if !found {
+			missing = append(missing, locale)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"items": translations, "missing": missing})
+}
+
+func (h *Handler) SaveTranslation(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+	locale, ok := translationLocale(w, r.PathValue("locale"))
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
+
+	var input TranslationInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return
+	}
+
+	input.Title = strings.TrimSpace(input.Title)
+	input.Description = strings.TrimSpace(input.Description)
+	if err := validateTitle(input.Title); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := validateDescription(input.Description); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	t, err := h.repo.SaveTranslation(r.Context(), id, locale, input)
+	if err != nil {
+		if errors.Is(err, ErrProductNotFound) {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to save translation")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (h *Handler) DeleteTranslation(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+	locale, ok := translationLocale(w, r.PathValue("locale"))
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteTranslation(r.Context(), id, locale); err != nil {
+		if errors.Is(err, ErrProductNotFound) {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "translation not found")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to delete translation")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) ListMissingTranslations(w http.ResponseWriter, r *http.Request) {
+	raw := r.URL.Query().Get("locale")
+	if raw == "" {
+		raw = LocaleEnglish
+	}
+	locale, ok := translationLocale(w, raw)
+	if !ok {
+		return
+	}
+
+	params, ok := parsePageParams(w, r)
+	if !ok {
+		return
+	}
+
+	page, err := h.repo.MissingTranslations(r.Context(), locale, params.Limit, params.Cursor)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to list missing translations")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (h *Handler) localize(w http.ResponseWriter, r *http.Request, products ...*Product) error {
+	locale := requestLocale(r)
+	w.Header().Set("Content-Language", locale)
+	return h.repo.Localize(r.Context(), locale, products...)
+}
+
+func requestLocale(r *http.Request) string {
+	if raw := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("lang"))); raw != "" {
+		if slices.Contains(supportedLocales, raw) {
+			return raw
+		}
+		return defaultLocale
+	}
+	if isAdmin(r) {
+		return defaultLocale
+	}
+
+	tags, _, err := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if err != nil || len(tags) == 0 {
+		return defaultLocale
+	}
+	_, index, confidence := localeMatcher.Match(tags...)
+	if confidence == language.No {
+		return defaultLocale
+	}
+	return supportedLocales[index]
+}
+
+func translationLocale(w http.ResponseWriter, raw string) (string, bool) {
+	locale := strings.ToLower(strings.TrimSpace(raw))
+	if !slices.Contains(supportedLocales, locale) || locale == defaultLocale {
+		writeError(w, http.StatusBadRequest, "locale must be en; es is the product's own title and description")
+		return "", false
+	}
+	return locale, true
+}
@@ -0,0 +1,209 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const missingTranslationCursorSort = "missing_translations"
+
+func (r *Repository) ListTranslations(ctx context.Context, productID string) ([]Translation, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT locale, title, description, updated_at
+		FROM product_translations
+		WHERE product_id = $1
+		ORDER BY locale ASC
+	`, productID)
+	if err != nil {
+		return nil, fmt.Errorf("query translations: %w", err)
+	}
+	defer rows.Close()
+
+	translations := make([]Translation, 0)
+	for rows.Next() {
+		var t Translation
+		if err := rows.Scan(&t.Locale, &t.Title, &t.Description, &t.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scan translation: %w", err)
+		}
+		translations = append(translations, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate translations: %w", err)
+	}
+
+	return translations, nil
+}
+
+func (r *Repository) SaveTranslation(ctx context.Context, productID, locale string, input TranslationInput) (Translation, error) {
+	now := time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Translation{}, fmt.Errorf("begin save translation tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := touchProduct(ctx, tx, productID, now); err != nil {
+		return Translation{}, err
+	}
+
+	t := Translation{Locale: locale}
+	if err := tx.QueryRowContext(ctx, `
+		INSERT INTO product_translations (product_id, locale, title, description, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		ON CONFLICT (product_id, locale) DO UPDATE
+		SET title = EXCLUDED.title, description = EXCLUDED.description, updated_at = EXCLUDED.updated_at
+		RETURNING title, description, updated_at
+	`, productID, locale, input.Title, input.Description, now).Scan(&t.Title, &t.Description, &t.UpdatedAt); err != nil {
+		return Translation{}, fmt.Errorf("save translation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Translation{}, fmt.Errorf("commit save translation tx: %w", err)
+	}
+
+	return t, nil
+}
+
+func (r *Repository) DeleteTranslation(ctx context.Context, productID, locale string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin delete translation tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := touchProduct(ctx, tx, productID, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM product_translations WHERE product_id = $1 AND locale = $2`, productID, locale)
+	if err != nil {
+		return fmt.Errorf("delete translation: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit delete translation tx: %w", err)
+	}
+
+	return nil
+}
+
+func (r *Repository) MissingTranslations(ctx context.Context, locale string, limit int, rawCursor string) (MissingTranslationPage, error) {
+	if limit <= 0 || limit > maxListLimit {
+		limit = defaultListLimit
+	}
+
+	var q listQuery
+	localeArg := q.arg(locale)
+	q.where("p.deleted_at IS NULL")
+	q.where("(t.product_id IS NULL OR (p.description <> '' AND t.description = ''))")
+	if rawCursor != "" {
+		c, err := decodeCursor(rawCursor)
+		if err != nil || c.Sort != missingTranslationCursorSort {
+			return MissingTranslationPage{}, ErrInvalidCursor
+		}
+		q.where("p.id < " + q.arg(c.ID) + "::uuid")
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT p.id, p.slug, p.title, t.product_id IS NULL, p.description <> '' AND COALESCE(t.description, '') = ''
+		FROM products p
+		LEFT JOIN product_translations t ON t.product_id = p.id AND t.locale = `+localeArg+`
+		`+q.whereClause()+`
+		ORDER BY p.id DESC
+		LIMIT `+q.arg(limit+1), q.args...)
+	if err != nil {
+		return MissingTranslationPage{}, fmt.Errorf("query missing translations: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]MissingTranslation, 0, limit)
+	for rows.Next() {
+		var item MissingTranslation
+		var missingTitle, missingDescription bool
+		if err := rows.Scan(&item.ProductID, &item.Slug, &item.Title, &missingTitle, &missingDescription); err != nil {
+			return MissingTranslationPage{}, fmt.Errorf("scan missing translation: %w", err)
+		}
+		item.Missing = make([]string, 0, 2)
+		if missingTitle {
+			item.Missing = append(item.Missing, "title")
+		}
+		if missingDescription {
+			item.Missing = append(item.Missing, "description")
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return MissingTranslationPage{}, fmt.Errorf("iterate missing translations: %w", err)
+	}
+
+	page := MissingTranslationPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		next := encodeCursor(cursor{Sort: missingTranslationCursorSort, ID: page.Items[limit-1].ProductID})
+		page.NextCursor = &next
+	}
+
+	return page, nil
+}
+
+func (r *Repository) Localize(ctx context.Context, locale string, products ...*Product) error {
+	if locale == defaultLocale || len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT product_id, title, description
+		FROM product_translations
+		WHERE product_id = ANY($1::uuid[]) AND locale = $2
+	`, ids, locale)
+	if err != nil {
+		return fmt.Errorf("query product translations: %w", err)
+	}
+	defer rows.Close()
+
+	translations := make(map[string]TranslationInput, len(products))
+	for rows.Next() {
+		var productID string
+		var t TranslationInput
+		if err := rows.Scan(&productID, &t.Title, &t.Description); err != nil {
+			return fmt.Errorf("scan product translation: %w", err)
+		}
+		translations[productID] = t
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate product translations: %w", err)
+	}
+
+	for _, p := range products {
+		t, ok := translations[p.ID]
+		if !ok {
+			continue
+		}
+		p.Locale = locale
+		p.Title = t.Title
+		if t.Description != "" {
+			p.Description = t.Description
+		}
+	}
+
+	return nil
+}
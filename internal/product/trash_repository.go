@@ -0,0 +1,147 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const trashCursorSort = "trash"
+
+var trashSort = sortOption{column: "p.deleted_at", cast: "timestamptz", desc: true}
+
+func (r *Repository) ListTrash(ctx context.Context, limit int, rawCursor string) (Page, error) {
+	if limit <= 0 || limit > maxListLimit {
+		limit = defaultListLimit
+	}
+
+	var q listQuery
+	q.where("p.deleted_at IS NOT NULL")
+	if rawCursor != "" {
+		c, err := decodeCursor(rawCursor)
+		if err != nil || c.Sort != trashCursorSort {
+			return Page{}, ErrInvalidCursor
+		}
+		q.where(trashSort.seek(q.arg(c.Value), q.arg(c.ID)))
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+productColumns+`
+		FROM products p
+		`+q.whereClause()+`
+		ORDER BY `+trashSort.orderBy()+`
+		LIMIT `+q.arg(limit+1), q.args...)
+	if err != nil {
+		return Page{}, fmt.Errorf("query trashed products: %w", err)
+	}
+	defer rows.Close()
+
+	products := make([]Product, 0, limit)
+	for rows.Next() {
+		p, err := scanProduct(rows)
+		if err != nil {
+			return Page{}, fmt.Errorf("scan trashed product: %w", err)
+		}
+		products = append(products, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return Page{}, fmt.Errorf("iterate trashed products: %w", err)
+	}
+
+	page := Page{Items: products}
+	if len(products) > limit {
+		page.Items = products[:limit]
+		last := page.Items[len(page.Items)-1]
+		next := encodeCursor(cursor{Sort: trashCursorSort, Value: last.DeletedAt.Format(time.RFC3339Nano), ID: last.ID})
+		page.NextCursor = &next
+	}
+
+	if err := r.hydrate(ctx, productPointers(page.Items)...); err != nil {
+		return Page{}, err
+	}
+
+	return page, nil
+}
+
+func (r *Repository) Restore(ctx context.Context, id string) (Product, error) {
+	now := time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Product{}, fmt.Errorf("begin product restore tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var deletedAt time.Time
+	if err := tx.QueryRowContext(ctx, `
+		SELECT deleted_at
+		FROM products
+		WHERE id = $1 AND deleted_at IS NOT NULL
+		FOR UPDATE
+	`, id).Scan(&deletedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Product{}, err
+		}
+		return Product{}, fmt.Errorf("lock trashed product: %w", err)
+	}
+
+	p, err := scanProduct(tx.QueryRowContext(ctx, `
+		UPDATE products AS p
+		SET deleted_at = NULL, updated_at = $2
+		WHERE p.id = $1
+		RETURNING `+productColumns+`
+	`, id, now))
+	if err != nil {
+		return Product{}, fmt.Errorf("restore product: %w", err)
+	}
+
+	changes := map[string]RevisionChange{"deleted_at": {From: deletedAt, To: nil}}
+	if err := recordRevision(ctx, tx, p, RevisionRestore, changes, now); err != nil {
+		return Product{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Product{}, fmt.Errorf("commit product restore tx: %w", err)
+	}
+
+	if err := r.hydrate(ctx, &p); err != nil {
+		return Product{}, err
+	}
+
+	return p, nil
+}
+
+func (r *Repository) PurgeTrash(ctx context.Context, retention time.Duration, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		batchSize = 500
+	}
+	if retention <= 0 {
+		retention = 30 * 24 * time.Hour
+	}
+
+	res, err := r.db.ExecContext(ctx, `
+		WITH expired AS (
+			SELECT id
+			FROM products
+			WHERE deleted_at IS NOT NULL AND deleted_at < $1
+			ORDER BY deleted_at ASC
+			LIMIT $2
+		)
+		DELETE FROM products p
+		USING expired
+		WHERE p.id = expired.id
+	`, time.Now().UTC().Add(-retention), batchSize)
+	if err != nil {
+		return 0, fmt.Errorf("purge trashed products: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("purged products rows affected: %w", err)
+	}
+
+	return affected, nil
+}
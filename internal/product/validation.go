@@ -0,0 +1,407 @@
+package product
+
+import (
+	"errors"
+	"math"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"store-serverless/internal/money"
+
+	"github.com/google/uuid"
+)
+
+var allowedURLChars = regexp.MustCompile(`^[A-Za-z0-9\-._~:/?#\[\]@!$&'()*+,;=%]+$`)
+var allowedHost = regexp.MustCompile(`^[A-Za-z0-9.-]+$`)
+
+var skuRegex = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,63}$`)
+var optionKeyRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,31}$`)
+var attributeKeyRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+const (
+	maxStockQuantity     = 1_000_000
+	maxVariantOptions    = 5
+	maxProductAttributes = 50
+	maxAllowedValues     = 100
+	maxAttributeText     = 200
+	maxProductImages     = 20
+	maxImageDimension    = 20_000
+	maxProductRelations  = 50
+)
+
+var stockReasons = []string{"restock", "sale", "return", "damage", "correction"}
+
+var relationKinds = []string{RelationRelated, RelationAccessory, RelationUpsell}
+
+func normalizeInput(input ProductInput) ProductInput {
+	input.Currency = strings.ToUpper(strings.TrimSpace(input.Currency))
+	if input.Currency == "" {
+		input.Currency = money.DefaultCurrency
+	}
+	input.Title = strings.TrimSpace(input.Title)
+	input.Description = strings.TrimSpace(input.Description)
+	input.ImageURL = strings.TrimSpace(input.ImageURL)
+	input.Status = strings.ToLower(strings.TrimSpace(input.Status))
+	input.SKU = normalizeSKU(input.SKU)
+	return input
+}
+
+func validateInput(input ProductInput) error {
+	if err := validateTitle(input.Title); err != nil {
+		return err
+	}
+	if err := validateDescription(input.Description); err != nil {
+		return err
+	}
+	if err := validateImageURL(input.ImageURL); err != nil {
+		return err
+	}
+	if err := validatePrice(input.Price); err != nil {
+		return err
+	}
+	if err := validateCurrency(input.Currency); err != nil {
+		return err
+	}
+	if err := validateSale(input); err != nil {
+		return err
+	}
+	if err := validateStatus(input.Status); err != nil {
+		return err
+	}
+	if err := validateProductSKU(input.SKU); err != nil {
+		return err
+	}
+	return validateLowStockThreshold(input.LowStockThreshold)
+}
+
+func validateTitle(title string) error {
+	if title == "" {
+		return errors.New("title is required")
+	}
+	if !utf8.ValidString(title) || len(title) > 150 {
+		return errors.New("title is invalid")
+	}
+	return nil
+}
+
+func validateDescription(description string) error {
+	if !utf8.ValidString(description) || len(description) > 1000 {
+		return errors.New("description is invalid")
+	}
+	return nil
+}
+
+func validateImageURL(imageURL string) error {
+	if imageURL == "" {
+		return errors.New("image_url is required")
+	}
+	if len(imageURL) > 500 || !isASCII(imageURL) {
+		return errors.New("image_url contains invalid characters")
+	}
+	if !allowedURLChars.MatchString(imageURL) {
+		return errors.New("image_url contains invalid characters")
+	}
+	parsedURL, err := url.ParseRequestURI(imageURL)
+	if err != nil || parsedURL.Scheme == "" || parsedURL.Host == "" {
+		return errors.New("image_url must be a valid link")
+	}
+	if parsedURL.Scheme != "http" && parsedURL.Scheme != "https" {
+		return errors.New("image_url must start with http or https")
+	}
+	if parsedURL.User != nil || !allowedHost.MatchString(parsedURL.Hostname()) {
+		return errors.New("image_url host is invalid")
+	}
+	return nil
+}
+
+func validatePrice(price money.Amount) error {
+	if price < 0 {
+		return errors.New("price must be >= 0")
+	}
+	return nil
+}
+
+func validateSale(input ProductInput) error {
+	if input.SalePrice != nil {
+		if *input.SalePrice < 0 {
+			return errors.New("sale_price must be >= 0")
+		}
+		if *input.SalePrice >= input.Price {
+			return errors.New("sale_price must be lower than price")
+		}
+	}
+	if input.SaleStartsAt != nil && input.SaleEndsAt != nil && !input.SaleEndsAt.After(*input.SaleStartsAt) {
+		return errors.New("sale_ends_at must be after sale_starts_at")
+	}
+	return nil
+}
+
+func normalizeSKU(sku *string) *string {
+	if sku == nil {
+		return nil
+	}
+	trimmed := strings.TrimSpace(*sku)
+	if trimmed == "" {
+		return nil
+	}
+	return &trimmed
+}
+
+func validateProductSKU(sku *string) error {
+	if sku != nil && !skuRegex.MatchString(*sku) {
+		return errSKUFormat
+	}
+	return nil
+}
+
+func validateStatus(status string) error {
+	if status != "" && !validStatus(status) {
+		return errInvalidStatus
+	}
+	return nil
+}
+
+var errSKUFormat = errors.New("sku must be 1-64 letters, digits, dots, dashes or underscores")
+
+var errInvalidStatus = errors.New("status must be one of draft, published, archived")
+
+func validStatus(status string) bool {
+	switch status {
+	case StatusDraft, StatusPublished, StatusArchived:
+		return true
+	}
+	return false
+}
+
+func validateCurrency(currency string) error {
+	if !money.ValidCurrency(currency) {
+		return errors.New("currency must be an ISO-4217 code")
+	}
+	return nil
+}
+
+func amountError(err error) (error, bool) {
+	switch {
+	case errors.Is(err, money.ErrTooManyDecimals):
+		return errors.New("amounts must have at most 2 decimals"), true
+	case errors.Is(err, money.ErrInvalidAmount), errors.Is(err, money.ErrOutOfRange):
+		return errors.New("amounts must be decimal numbers up to 9999999999.99"), true
+	default:
+		return nil, false
+	}
+}
+
+func validateLowStockThreshold(threshold int) error {
+	if threshold < 0 || threshold > maxStockQuantity {
+		return errors.New("low_stock_threshold is invalid")
+	}
+	return nil
+}
+
+func validateStockAdjustment(input StockAdjustmentInput) error {
+	if input.Delta == 0 {
+		return errors.New("delta must not be zero")
+	}
+	if input.Delta < -maxStockQuantity || input.Delta > maxStockQuantity {
+		return errors.New("delta is out of range")
+	}
+	if !slices.Contains(stockReasons, input.Reason) {
+		return errors.New("reason must be one of " + strings.Join(stockReasons, ", "))
+	}
+	if !utf8.ValidString(input.Note) || len(input.Note) > 500 {
+		return errors.New("note is invalid")
+	}
+	return nil
+}
+
+func normalizeRelations(productID string, relations []Relation) ([]Relation, error) {
+	if len(relations) > maxProductRelations {
+		return nil, errors.New("a product can have at most 50 relations")
+	}
+
+	seen := make(map[Relation]struct{}, len(relations))
+	normalized := make([]Relation, 0, len(relations))
+	for _, relation := range relations {
+		parsed, err := uuid.Parse(strings.TrimSpace(relation.ProductID))
+		if err != nil {
+			return nil, errors.New("invalid related product id")
+		}
+		relation.ProductID = parsed.String()
+		relation.Kind = strings.ToLower(strings.TrimSpace(relation.Kind))
+		if relation.Kind == "" {
+			relation.Kind = RelationRelated
+		}
+		if !slices.Contains(relationKinds, relation.Kind) {
+			return nil, errors.New("kind must be one of related, accessory, upsell")
+		}
+		if relation.ProductID == productID {
+			return nil, errors.New("a product cannot be related to itself")
+		}
+
+		key := Relation{ProductID: relation.ProductID, Kind: relation.Kind}
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		seen[key] = struct{}{}
+		relation.Position = len(normalized)
+		normalized = append(normalized, relation)
+	}
+	return normalized, nil
+}
+
+func normalizeImageInput(input ImageInput) ImageInput {
+	input.ImageURL = strings.TrimSpace(input.ImageURL)
+	input.AltText = strings.TrimSpace(input.AltText)
+	return input
+}
+
+func validateImageInput(input ImageInput) error {
+	if err := validateImageURL(input.ImageURL); err != nil {
+		return err
+	}
+	if !utf8.ValidString(input.AltText) || len(input.AltText) > 250 {
+		return errors.New("alt_text must be at most 250 characters")
+	}
+	if input.Width != nil && (*input.Width <= 0 || *input.Width > maxImageDimension) {
+		return errors.New("width is invalid")
+	}
+	if input.Height != nil && (*input.Height <= 0 || *input.Height > maxImageDimension) {
+		return errors.New("height is invalid")
+	}
+	return nil
+}
+
+func normalizeVariantInput(input VariantInput) VariantInput {
+	input.SKU = strings.TrimSpace(input.SKU)
+	input.ImageURL = strings.TrimSpace(input.ImageURL)
+	options := make(map[string]string, len(input.Options))
+	for key, value := range input.Options {
+		options[strings.TrimSpace(strings.ToLower(key))] = strings.TrimSpace(value)
+	}
+	input.Options = options
+	return input
+}
+
+func validateVariantInput(input VariantInput) error {
+	if !skuRegex.MatchString(input.SKU) {
+		return errSKUFormat
+	}
+	if len(input.Options) == 0 || len(input.Options) > maxVariantOptions {
+		return errors.New("options must have between 1 and 5 entries")
+	}
+	for key, value := range input.Options {
+		if !optionKeyRegex.MatchString(key) {
+			return errors.New("option names must be lowercase identifiers")
+		}
+		if value == "" || !utf8.ValidString(value) || len(value) > 50 {
+			return errors.New("option " + key + " has an invalid value")
+		}
+	}
+	if input.Price != nil {
+		if err := validatePrice(*input.Price); err != nil {
+			return err
+		}
+	}
+	if input.ImageURL != "" {
+		if err := validateImageURL(input.ImageURL); err != nil {
+			return err
+		}
+	}
+	if input.Position < 0 || input.Position > 10_000 {
+		return errors.New("position is invalid")
+	}
+	return nil
+}
+
+func isASCII(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] < 32 || value[i] > 126 {
+			return false
+		}
+	}
+	return true
+}
+
+func normalizeAttributeInput(input AttributeDefinitionInput) AttributeDefinitionInput {
+	input.Label = strings.TrimSpace(input.Label)
+	input.Type = strings.ToLower(strings.TrimSpace(input.Type))
+	input.Unit = strings.TrimSpace(input.Unit)
+	values := make([]string, 0, len(input.AllowedValues))
+	for _, value := range input.AllowedValues {
+		values = append(values, strings.TrimSpace(value))
+	}
+	input.AllowedValues = values
+	return input
+}
+
+func validateAttributeInput(input AttributeDefinitionInput) error {
+	if input.Label == "" || !utf8.ValidString(input.Label) || len(input.Label) > 100 {
+		return errors.New("label is required and must be at most 100 characters")
+	}
+	if !utf8.ValidString(input.Unit) || len(input.Unit) > 20 {
+		return errors.New("unit must be at most 20 characters")
+	}
+
+	switch input.Type {
+	case AttributeText, AttributeNumber, AttributeBoolean:
+		if len(input.AllowedValues) > 0 {
+			return errors.New("allowed_values is only valid for enum attributes")
+		}
+	case AttributeEnum:
+		if len(input.AllowedValues) == 0 || len(input.AllowedValues) > maxAllowedValues {
+			return errors.New("enum attributes need between 1 and 100 allowed_values")
+		}
+		seen := make(map[string]struct{}, len(input.AllowedValues))
+		for _, value := range input.AllowedValues {
+			if value == "" || !utf8.ValidString(value) || len(value) > maxAttributeText {
+				return errors.New("allowed_values entries must be 1-200 characters")
+			}
+			if _, dup := seen[value]; dup {
+				return errors.New("allowed_values must be unique")
+			}
+			seen[value] = struct{}{}
+		}
+	default:
+		return errors.New("type must be one of text, number, boolean, enum")
+	}
+
+	return nil
+}
+
+func validateAttributes(schema map[string]AttributeDefinition, attributes map[string]any) error {
+	if len(attributes) > maxProductAttributes {
+		return errors.New("a product can have at most 50 attributes")
+	}
+
+	for key, value := range attributes {
+		definition, ok := schema[key]
+		if !ok {
+			return errors.New("unknown attribute: " + key)
+		}
+		if !validAttributeValue(definition, value) {
+			return errors.New("attribute " + key + " must be a valid " + definition.Type + " value")
+		}
+	}
+	return nil
+}
+
+func validAttributeValue(definition AttributeDefinition, value any) bool {
+	switch definition.Type {
+	case AttributeText:
+		text, ok := value.(string)
+		return ok && text != "" && utf8.ValidString(text) && len(text) <= maxAttributeText
+	case AttributeNumber:
+		number, ok := value.(float64)
+		return ok && !math.IsInf(number, 0) && !math.IsNaN(number)
+	case AttributeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case AttributeEnum:
+		text, ok := value.(string)
+		return ok && slices.Contains(definition.AllowedValues, text)
+	}
+	return false
+}
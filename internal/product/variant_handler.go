@@ -0,0 +1,169 @@
+package product
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) CreateVariant(w http.ResponseWriter, r *http.Request) {
+	productID := r.PathValue("id")
+	if _, err := uuid.Parse(productID); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	input, ok := parseVariantInput(w, r)
+	if !ok {
+		return
+	}
+
+	if input.ImageURL != "" {
+		if !h.uploadVariantImage(w, r, &input) {
+			return
+		}
+	}
+
+	v, err := h.repo.CreateVariant(r.Context(), productID, input)
+	if err != nil {
+		if writeVariantError(w, err) {
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to create variant")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, v)
+}
+
+func (h *Handler) UpdateVariant(w http.ResponseWriter, r *http.Request) {
+	productID := r.PathValue("id")
+	variantID := r.PathValue("variantID")
+	if _, err := uuid.Parse(productID); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+	if _, err := uuid.Parse(variantID); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid variant id")
+		return
+	}
+
+	input, ok := parseVariantInput(w, r)
+	if !ok {
+		return
+	}
+
+	current, err := h.repo.GetVariant(r.Context(), productID, variantID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "variant not found")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to get variant")
+		return
+	}
+
+	if input.ImageURL != "" && input.ImageURL != current.ImageURL {
+		if !h.uploadVariantImage(w, r, &input) {
+			return
+		}
+	}
+
+	v, err := h.repo.UpdateVariant(r.Context(), productID, variantID, input)
+	if err != nil {
+		if writeVariantError(w, err) {
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to update variant")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, v)
+}
+
+func (h *Handler) DeleteVariant(w http.ResponseWriter, r *http.Request) {
+	productID := r.PathValue("id")
+	variantID := r.PathValue("variantID")
+	if _, err := uuid.Parse(productID); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+	if _, err := uuid.Parse(variantID); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid variant id")
+		return
+	}
+
+	if err := h.repo.DeleteVariant(r.Context(), productID, variantID); err != nil {
+		if writeVariantError(w, err) {
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to delete variant")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) uploadVariantImage(w http.ResponseWriter, r *http.Request, input *VariantInput) bool {
+	if h.uploader == nil {
+		writeError(w, http.StatusInternalServerError, "image uploader is not configured")
+		return false
+	}
+
+	uploadedURL, err := h.uploader.UploadImage(r.Context(), input.ImageURL)
+	if err != nil {
+		sentry.CaptureException(err)
+		writeError(w, http.StatusBadGateway, "failed to upload image")
+		return false
+	}
+	input.ImageURL = uploadedURL
+	return true
+}
+
+func parseVariantInput(w http.ResponseWriter, r *http.Request) (VariantInput, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
+
+	var input VariantInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		if amountErr, ok := amountError(err); ok {
+			writeError(w, http.StatusBadRequest, amountErr.Error())
+			return VariantInput{}, false
+		}
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return VariantInput{}, false
+	}
+
+	input = normalizeVariantInput(input)
+	if err := validateVariantInput(input); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return VariantInput{}, false
+	}
+
+	return input, true
+}
+
+func writeVariantError(w http.ResponseWriter, err error) bool {
+	switch {
+	case errors.Is(err, ErrProductNotFound):
+		writeError(w, http.StatusNotFound, "product not found")
+	case errors.Is(err, sql.ErrNoRows):
+		writeError(w, http.StatusNotFound, "variant not found")
+	case errors.Is(err, ErrDuplicateSKU):
+		writeError(w, http.StatusConflict, "sku already exists")
+	case errors.Is(err, ErrDuplicateVariantOptions):
+		writeError(w, http.StatusConflict, "a variant with these options already exists")
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,219 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const variantColumns = `v.id, v.product_id, v.sku, v.options, v.price, v.image_url, v.stock_quantity, v.position, v.created_at, v.updated_at`
+
+func (r *Repository) GetVariant(ctx context.Context, productID, variantID string) (Variant, error) {
+	v, err := scanVariant(r.db.QueryRowContext(ctx, `
+		SELECT `+variantColumns+`
+		FROM product_variants v
+		WHERE v.product_id = $1 AND v.id = $2
+	`, productID, variantID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Variant{}, err
+		}
+		return Variant{}, fmt.Errorf("query variant: %w", err)
+	}
+
+	return v, nil
+}
+
+func (r *Repository) CreateVariant(ctx context.Context, productID string, input VariantInput) (Variant, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return Variant{}, fmt.Errorf("generate uuid v7: %w", err)
+	}
+
+	options, err := json.Marshal(input.Options)
+	if err != nil {
+		return Variant{}, fmt.Errorf("encode variant options: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Variant{}, fmt.Errorf("begin create variant tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := touchProduct(ctx, tx, productID, now); err != nil {
+		return Variant{}, err
+	}
+
+	v, err := scanVariant(tx.QueryRowContext(ctx, `
+		INSERT INTO product_variants AS v (id, product_id, sku, options, price, image_url, position, created_at, updated_at)
+		VALUES ($1, $2, $3, $4::jsonb, $5, $6, $7, $8, $8)
+		RETURNING `+variantColumns+`
+	`, id.String(), productID, input.SKU, string(options), input.Price, input.ImageURL, input.Position, now))
+	if err != nil {
+		return Variant{}, translateVariantError(err, "insert variant")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Variant{}, fmt.Errorf("commit create variant tx: %w", err)
+	}
+
+	return v, nil
+}
+
+func (r *Repository) UpdateVariant(ctx context.Context, productID, variantID string, input VariantInput) (Variant, error) {
+	options, err := json.Marshal(input.Options)
+	if err != nil {
+		return Variant{}, fmt.Errorf("encode variant options: %w", err)
+	}
+
+	now := time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Variant{}, fmt.Errorf("begin update variant tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := touchProduct(ctx, tx, productID, now); err != nil {
+		return Variant{}, err
+	}
+
+	v, err := scanVariant(tx.QueryRowContext(ctx, `
+		UPDATE product_variants AS v
+		SET sku = $3, options = $4::jsonb, price = $5, image_url = $6, position = $7, updated_at = $8
+		WHERE v.product_id = $1 AND v.id = $2
+		RETURNING `+variantColumns+`
+	`, productID, variantID, input.SKU, string(options), input.Price, input.ImageURL, input.Position, now))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Variant{}, err
+		}
+		return Variant{}, translateVariantError(err, "update variant")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Variant{}, fmt.Errorf("commit update variant tx: %w", err)
+	}
+
+	return v, nil
+}
+
+func (r *Repository) DeleteVariant(ctx context.Context, productID, variantID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin delete variant tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := touchProduct(ctx, tx, productID, time.Now().UTC()); err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM product_variants WHERE product_id = $1 AND id = $2`, productID, variantID)
+	if err != nil {
+		return fmt.Errorf("delete variant: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit delete variant tx: %w", err)
+	}
+
+	return nil
+}
+
+func loadVariants(ctx context.Context, q queryer, productIDs []string) (map[string][]Variant, error) {
+	rows, err := q.QueryContext(ctx, `
+		SELECT `+variantColumns+`
+		FROM product_variants v
+		WHERE v.product_id = ANY($1::uuid[])
+		ORDER BY v.product_id, v.position ASC, v.id ASC
+	`, productIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query variants: %w", err)
+	}
+	defer rows.Close()
+
+	variants := make(map[string][]Variant, len(productIDs))
+	for rows.Next() {
+		v, err := scanVariant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan variant: %w", err)
+		}
+		variants[v.ProductID] = append(variants[v.ProductID], v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate variants: %w", err)
+	}
+
+	return variants, nil
+}
+
+func touchProduct(ctx context.Context, tx *sql.Tx, productID string, now time.Time) error {
+	res, err := tx.ExecContext(ctx, `UPDATE products SET updated_at = $2 WHERE id = $1 AND deleted_at IS NULL`, productID, now)
+	if err != nil {
+		return fmt.Errorf("touch product: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if affected == 0 {
+		return ErrProductNotFound
+	}
+
+	return nil
+}
+
+func scanVariant(row rowScanner) (Variant, error) {
+	var v Variant
+	var options []byte
+	var stock sql.NullInt64
+	if err := row.Scan(&v.ID, &v.ProductID, &v.SKU, &options, &v.Price, &v.ImageURL, &stock, &v.Position, &v.CreatedAt, &v.UpdatedAt); err != nil {
+		return Variant{}, err
+	}
+	if err := json.Unmarshal(options, &v.Options); err != nil {
+		return Variant{}, fmt.Errorf("decode variant options: %w", err)
+	}
+	if stock.Valid {
+		quantity := int(stock.Int64)
+		v.StockQuantity = &quantity
+	}
+	v.InStock, v.AvailableQuantity = stockAvailability(v.StockQuantity, false)
+	return v, nil
+}
+
+func translateVariantError(err error, action string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		if pgErr.ConstraintName == "product_variants_options_unique" {
+			return ErrDuplicateVariantOptions
+		}
+		return ErrDuplicateSKU
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
+
+var (
+	ErrProductNotFound         = errors.New("product not found")
+	ErrDuplicateSKU            = errors.New("sku already exists")
+	ErrDuplicateVariantOptions = errors.New("variant options already exist for this product")
+)
@@ -0,0 +1,172 @@
+package promotion
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+)
+
+const (
+	maxJSONBodyBytes = 1 << 20
+	minPercentOff    = 1
+	maxPercentOff    = 90
+)
+
+type Handler struct {
+	repo *Repository
+}
+
+func NewHandler(repo *Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) ListPromotions(w http.ResponseWriter, r *http.Request) {
+	activeOnly := false
+	if raw := r.URL.Query().Get("active"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "active must be a boolean")
+			return
+		}
+		activeOnly = parsed
+	}
+
+	promotions, err := h.repo.List(r.Context(), activeOnly)
+	if err != nil {
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to list promotions")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"items": promotions})
+}
+
+func (h *Handler) CreatePromotion(w http.ResponseWriter, r *http.Request) {
+	input, ok := parseInput(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := h.repo.Create(r.Context(), input)
+	if err != nil {
+		if errors.Is(err, ErrCategoryNotFound) {
+			writeError(w, http.StatusBadRequest, "category not found")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to create promotion")
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, p)
+}
+
+func (h *Handler) UpdatePromotion(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid promotion id")
+		return
+	}
+
+	input, ok := parseInput(w, r)
+	if !ok {
+		return
+	}
+
+	p, err := h.repo.Update(r.Context(), id, input)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "promotion not found")
+			return
+		}
+		if errors.Is(err, ErrCategoryNotFound) {
+			writeError(w, http.StatusBadRequest, "category not found")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to update promotion")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (h *Handler) DeletePromotion(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid promotion id")
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "promotion not found")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to delete promotion")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func parseInput(w http.ResponseWriter, r *http.Request) (PromotionInput, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
+
+	var input PromotionInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return PromotionInput{}, false
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+
+	if input.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return PromotionInput{}, false
+	}
+	if !utf8.ValidString(input.Name) || len(input.Name) > 100 {
+		writeError(w, http.StatusBadRequest, "name is invalid")
+		return PromotionInput{}, false
+	}
+	parsed, err := uuid.Parse(strings.TrimSpace(input.CategoryID))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid category_id")
+		return PromotionInput{}, false
+	}
+	input.CategoryID = parsed.String()
+	if input.PercentOff < minPercentOff || input.PercentOff > maxPercentOff {
+		writeError(w, http.StatusBadRequest, "percent_off must be between 1 and 90")
+		return PromotionInput{}, false
+	}
+	if input.StartsAt.IsZero() {
+		writeError(w, http.StatusBadRequest, "starts_at is required")
+		return PromotionInput{}, false
+	}
+	if input.EndsAt != nil && !input.EndsAt.After(input.StartsAt) {
+		writeError(w, http.StatusBadRequest, "ends_at must be after starts_at")
+		return PromotionInput{}, false
+	}
+
+	return input, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,27 @@
+package promotion
+
+import "time"
+
+type Promotion struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	CategoryID string     `json:"category_id"`
+	PercentOff int        `json:"percent_off"`
+	StartsAt   time.Time  `json:"starts_at"`
+	EndsAt     *time.Time `json:"ends_at"`
+	Active     bool       `json:"active"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+type PromotionInput struct {
+	Name       string     `json:"name"`
+	CategoryID string     `json:"category_id"`
+	PercentOff int        `json:"percent_off"`
+	StartsAt   time.Time  `json:"starts_at"`
+	EndsAt     *time.Time `json:"ends_at"`
+}
+
+func (p *Promotion) computeActive(now time.Time) {
+	p.Active = !now.Before(p.StartsAt) && (p.EndsAt == nil || now.Before(*p.EndsAt))
+}
@@ -0,0 +1,156 @@
+package promotion
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const pgForeignKeyViolation = "23503"
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) List(ctx context.Context, activeOnly bool) ([]Promotion, error) {
+	now := time.Now().UTC()
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+promotionColumns+`
+		FROM promotions
+		WHERE NOT $1 OR (starts_at <= $2 AND (ends_at IS NULL OR ends_at > $2))
+		ORDER BY starts_at DESC, id DESC
+	`, activeOnly, now)
+	if err != nil {
+		return nil, fmt.Errorf("query promotions: %w", err)
+	}
+	defer rows.Close()
+
+	promotions := make([]Promotion, 0)
+	for rows.Next() {
+		p, err := scanPromotion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan promotion: %w", err)
+		}
+		p.computeActive(now)
+		promotions = append(promotions, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate promotions: %w", err)
+	}
+
+	return promotions, nil
+}
+
+func (r *Repository) Create(ctx context.Context, input PromotionInput) (Promotion, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return Promotion{}, fmt.Errorf("generate uuid v7: %w", err)
+	}
+
+	now := time.Now().UTC()
+	p, err := scanPromotion(r.db.QueryRowContext(ctx, `
+		INSERT INTO promotions (id, name, category_id, percent_off, starts_at, ends_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $7)
+		RETURNING `+promotionColumns+`
+	`, id.String(), input.Name, input.CategoryID, input.PercentOff, input.StartsAt, input.EndsAt, now))
+	if err != nil {
+		return Promotion{}, translateWriteError(err, "insert promotion")
+	}
+
+	p.computeActive(now)
+	return p, nil
+}
+
+func (r *Repository) Update(ctx context.Context, id string, input PromotionInput) (Promotion, error) {
+	now := time.Now().UTC()
+	p, err := scanPromotion(r.db.QueryRowContext(ctx, `
+		UPDATE promotions
+		SET name = $2, category_id = $3, percent_off = $4, starts_at = $5, ends_at = $6, updated_at = $7
+		WHERE id = $1
+		RETURNING `+promotionColumns+`
+	`, id, input.Name, input.CategoryID, input.PercentOff, input.StartsAt, input.EndsAt, now))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Promotion{}, err
+		}
+		return Promotion{}, translateWriteError(err, "update promotion")
+	}
+
+	p.computeActive(now)
+	return p, nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin promotion delete tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM promotions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete promotion: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("rows affected: %w", err)
+	}
+	if affected == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO catalog_changes (scope, changed_at)
+		VALUES ('promotions', $1)
+		ON CONFLICT (scope) DO UPDATE SET changed_at = GREATEST(catalog_changes.changed_at, EXCLUDED.changed_at)
+	`, time.Now().UTC()); err != nil {
+		return fmt.Errorf("record catalog change: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit promotion delete tx: %w", err)
+	}
+
+	return nil
+}
+
+const promotionColumns = `id, name, category_id, percent_off, starts_at, ends_at, created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanPromotion(row rowScanner) (Promotion, error) {
+	var p Promotion
+	var endsAt sql.NullTime
+	if err := row.Scan(&p.ID, &p.Name, &p.CategoryID, &p.PercentOff, &p.StartsAt, &endsAt, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return Promotion{}, err
+	}
+	p.StartsAt = p.StartsAt.UTC()
+	if endsAt.Valid {
+		t := endsAt.Time.UTC()
+		p.EndsAt = &t
+	}
+	return p, nil
+}
+
+func translateWriteError(err error, action string) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+		return ErrCategoryNotFound
+	}
+	return fmt.Errorf("%s: %w", action, err)
+}
+
+var ErrCategoryNotFound = errors.New("category not found")
@@ -0,0 +1,274 @@
+package review
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/google/uuid"
+)
+
+const (
+	maxJSONBodyBytes  = 16 << 10
+	orderTokenHeader  = "X-Order-Token"
+	minRating         = 1
+	maxRating         = 5
+	defaultAuthorName = "Anónimo"
+)
+
+var statuses = []string{StatusPending, StatusApproved, StatusRejected}
+
+type Handler struct {
+	repo      *Repository
+	purchases PurchaseVerifier
+}
+
+type PurchaseVerifier interface {
+	VerifyPurchase(ctx context.Context, orderID, token, productID string) (bool, error)
+}
+
+func NewHandler(repo *Repository) *Handler {
+	return &Handler{repo: repo}
+}
+
+func (h *Handler) WithPurchaseVerifier(purchases PurchaseVerifier) {
+	h.purchases = purchases
+}
+
+func (h *Handler) SubmitReview(w http.ResponseWriter, r *http.Request) {
+	productID := r.PathValue("id")
+	if _, err := uuid.Parse(productID); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	input, ok := parseInput(w, r)
+	if !ok {
+		return
+	}
+
+	verified := false
+	if input.OrderID != "" {
+		if h.purchases == nil {
+			writeError(w, http.StatusBadRequest, "verified reviews are not available")
+			return
+		}
+		var err error
+		verified, err = h.purchases.VerifyPurchase(r.Context(), input.OrderID, r.Header.Get(orderTokenHeader), productID)
+		if err != nil {
+			sentry.CaptureException(err)
+			writeError(w, http.StatusInternalServerError, "failed to verify purchase")
+			return
+		}
+		if !verified {
+			writeError(w, http.StatusForbidden, "order token does not match a paid order with this product")
+			return
+		}
+	}
+
+	rv, err := h.repo.Create(r.Context(), productID, input, verified)
+	if err != nil {
+		if errors.Is(err, ErrProductNotFound) {
+			writeError(w, http.StatusNotFound, "product not found")
+			return
+		}
+		if errors.Is(err, ErrAlreadyReviewed) {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to submit review")
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, rv)
+}
+
+func (h *Handler) ListProductReviews(w http.ResponseWriter, r *http.Request) {
+	productID := r.PathValue("id")
+	if _, err := uuid.Parse(productID); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid product id")
+		return
+	}
+
+	params, ok := parseListParams(w, r)
+	if !ok {
+		return
+	}
+	params.ProductID = productID
+	params.Status = StatusApproved
+
+	h.list(w, r, params)
+}
+
+func (h *Handler) ListReviews(w http.ResponseWriter, r *http.Request) {
+	params, ok := parseListParams(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	params.Status = strings.ToLower(strings.TrimSpace(query.Get("status")))
+	if params.Status == "" {
+		params.Status = StatusPending
+	}
+	if !slices.Contains(statuses, params.Status) {
+		writeError(w, http.StatusBadRequest, "status must be one of pending, approved, rejected")
+		return
+	}
+	if raw := strings.TrimSpace(query.Get("product_id")); raw != "" {
+		if _, err := uuid.Parse(raw); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid product_id")
+			return
+		}
+		params.ProductID = raw
+	}
+	params.Oldest = params.Status == StatusPending
+
+	h.list(w, r, params)
+}
+
+func (h *Handler) ApproveReview(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, StatusApproved)
+}
+
+func (h *Handler) RejectReview(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, StatusRejected)
+}
+
+func (h *Handler) DeleteReview(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid review id")
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "review not found")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to delete review")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) list(w http.ResponseWriter, r *http.Request, params ListParams) {
+	page, err := h.repo.List(r.Context(), params)
+	if err != nil {
+		if errors.Is(err, ErrInvalidCursor) {
+			writeError(w, http.StatusBadRequest, "invalid cursor")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to list reviews")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, page)
+}
+
+func (h *Handler) moderate(w http.ResponseWriter, r *http.Request, status string) {
+	id := r.PathValue("id")
+	if _, err := uuid.Parse(id); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid review id")
+		return
+	}
+
+	rv, err := h.repo.Moderate(r.Context(), id, status)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, http.StatusNotFound, "review not found")
+			return
+		}
+		sentry.CaptureException(err)
+		writeError(w, http.StatusInternalServerError, "failed to moderate review")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rv)
+}
+
+func parseInput(w http.ResponseWriter, r *http.Request) (ReviewInput, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxJSONBodyBytes)
+
+	var input ReviewInput
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&input); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid json body")
+		return ReviewInput{}, false
+	}
+
+	input.Title = strings.TrimSpace(input.Title)
+	input.Body = strings.TrimSpace(input.Body)
+	input.AuthorName = strings.TrimSpace(input.AuthorName)
+	input.OrderID = strings.TrimSpace(input.OrderID)
+	if input.AuthorName == "" {
+		input.AuthorName = defaultAuthorName
+	}
+
+	if input.Rating < minRating || input.Rating > maxRating {
+		writeError(w, http.StatusBadRequest, "rating must be between 1 and 5")
+		return ReviewInput{}, false
+	}
+	if !utf8.ValidString(input.Title) || utf8.RuneCountInString(input.Title) > 100 {
+		writeError(w, http.StatusBadRequest, "title must be at most 100 characters")
+		return ReviewInput{}, false
+	}
+	if input.Body == "" {
+		writeError(w, http.StatusBadRequest, "body is required")
+		return ReviewInput{}, false
+	}
+	if !utf8.ValidString(input.Body) || utf8.RuneCountInString(input.Body) > 2000 {
+		writeError(w, http.StatusBadRequest, "body must be at most 2000 characters")
+		return ReviewInput{}, false
+	}
+	if !utf8.ValidString(input.AuthorName) || utf8.RuneCountInString(input.AuthorName) > 60 {
+		writeError(w, http.StatusBadRequest, "author_name must be at most 60 characters")
+		return ReviewInput{}, false
+	}
+	if input.OrderID != "" {
+		if _, err := uuid.Parse(input.OrderID); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid order_id")
+			return ReviewInput{}, false
+		}
+	}
+
+	return input, true
+}
+
+func parseListParams(w http.ResponseWriter, r *http.Request) (ListParams, bool) {
+	query := r.URL.Query()
+	params := ListParams{Limit: defaultListLimit, Cursor: strings.TrimSpace(query.Get("cursor"))}
+	if raw := strings.TrimSpace(query.Get("limit")); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > maxListLimit {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("limit must be between 1 and %d", maxListLimit))
+			return ListParams{}, false
+		}
+		params.Limit = limit
+	}
+	return params, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
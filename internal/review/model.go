@@ -0,0 +1,43 @@
+package review
+
+import "time"
+
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+type Review struct {
+	ID          string     `json:"id"`
+	ProductID   string     `json:"product_id"`
+	Rating      int        `json:"rating"`
+	Title       string     `json:"title"`
+	Body        string     `json:"body"`
+	AuthorName  string     `json:"author_name"`
+	Verified    bool       `json:"verified"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ModeratedAt *time.Time `json:"moderated_at"`
+}
+
+type ReviewInput struct {
+	Rating     int    `json:"rating"`
+	Title      string `json:"title"`
+	Body       string `json:"body"`
+	AuthorName string `json:"author_name"`
+	OrderID    string `json:"order_id"`
+}
+
+type ListParams struct {
+	ProductID string
+	Status    string
+	Limit     int
+	Cursor    string
+	Oldest    bool
+}
+
+type Page struct {
+	Items      []Review `json:"items"`
+	NextCursor *string  `json:"next_cursor"`
+}
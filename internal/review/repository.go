@@ -0,0 +1,250 @@
+package review
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	defaultListLimit  = 20
+	maxListLimit      = 100
+	pgUniqueViolation = "23505"
+)
+
+type Repository struct {
+	db *sql.DB
+}
+
+func NewRepository(db *sql.DB) *Repository {
+	return &Repository{db: db}
+}
+
+func (r *Repository) List(ctx context.Context, params ListParams) (Page, error) {
+	limit := params.Limit
+	if limit <= 0 || limit > maxListLimit {
+		limit = defaultListLimit
+	}
+
+	args := make([]any, 0, 4)
+	arg := func(value any) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	conditions := []string{"status = " + arg(params.Status)}
+	if params.ProductID != "" {
+		conditions = append(conditions, "product_id = "+arg(params.ProductID))
+	}
+	order, seek := "DESC", "<"
+	if params.Oldest {
+		order, seek = "ASC", ">"
+	}
+	if params.Cursor != "" {
+		id, err := decodeCursor(params.Cursor)
+		if err != nil {
+			return Page{}, err
+		}
+		conditions = append(conditions, "id "+seek+" "+arg(id)+"::uuid")
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+reviewColumns+`
+		FROM product_reviews
+		WHERE `+strings.Join(conditions, " AND ")+`
+		ORDER BY id `+order+`
+		LIMIT `+arg(limit+1), args...)
+	if err != nil {
+		return Page{}, fmt.Errorf("query reviews: %w", err)
+	}
+	defer rows.Close()
+
+	reviews := make([]Review, 0, limit)
+	for rows.Next() {
+		rv, err := scanReview(rows)
+		if err != nil {
+			return Page{}, fmt.Errorf("scan review: %w", err)
+		}
+		reviews = append(reviews, rv)
+	}
+
+	if err := rows.Err(); err != nil {
+		return Page{}, fmt.Errorf("iterate reviews: %w", err)
+	}
+
+	page := Page{Items: reviews}
+	if len(reviews) > limit {
+		page.Items = reviews[:limit]
+		next := encodeCursor(page.Items[limit-1].ID)
+		page.NextCursor = &next
+	}
+
+	return page, nil
+}
+
+func (r *Repository) Create(ctx context.Context, productID string, input ReviewInput, verified bool) (Review, error) {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return Review{}, fmt.Errorf("generate uuid v7: %w", err)
+	}
+
+	var orderID *string
+	if verified {
+		orderID = &input.OrderID
+	}
+
+	rv, err := scanReview(r.db.QueryRowContext(ctx, `
+		INSERT INTO product_reviews (id, product_id, rating, title, body, author_name, order_id, verified, status, created_at)
+		SELECT $1, p.id, $3, $4, $5, $6, $7, $8, $9, $10
+		FROM products p
+		WHERE p.id = $2 AND p.deleted_at IS NULL
+			AND p.status = 'published' AND (p.publish_at IS NULL OR p.publish_at <= now())
+		RETURNING `+reviewColumns+`
+	`, id.String(), productID, input.Rating, input.Title, input.Body, input.AuthorName, orderID, verified, StatusPending, time.Now().UTC()))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Review{}, ErrProductNotFound
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return Review{}, ErrAlreadyReviewed
+		}
+		return Review{}, fmt.Errorf("insert review: %w", err)
+	}
+
+	return rv, nil
+}
+
+func (r *Repository) Moderate(ctx context.Context, id, status string) (Review, error) {
+	now := time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return Review{}, fmt.Errorf("begin moderate review tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	rv, err := scanReview(tx.QueryRowContext(ctx, `
+		UPDATE product_reviews
+		SET status = $2, moderated_at = $3
+		WHERE id = $1
+		RETURNING `+reviewColumns+`
+	`, id, status, now))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Review{}, err
+		}
+		return Review{}, fmt.Errorf("moderate review: %w", err)
+	}
+
+	if err := refreshRating(ctx, tx, rv.ProductID, now); err != nil {
+		return Review{}, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return Review{}, fmt.Errorf("commit moderate review tx: %w", err)
+	}
+
+	return rv, nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id string) error {
+	now := time.Now().UTC()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin delete review tx: %w", err)
+	}
+	defer tx.Rollback()
+
+	var productID string
+	if err := tx.QueryRowContext(ctx, `DELETE FROM product_reviews WHERE id = $1 RETURNING product_id`, id).Scan(&productID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		return fmt.Errorf("delete review: %w", err)
+	}
+
+	if err := refreshRating(ctx, tx, productID, now); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit delete review tx: %w", err)
+	}
+
+	return nil
+}
+
+func refreshRating(ctx context.Context, tx *sql.Tx, productID string, now time.Time) error {
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM products WHERE id = $1 FOR UPDATE`, productID); err != nil {
+		return fmt.Errorf("lock product rating: %w", err)
+	}
+
+	var average float64
+	var count int
+	if err := tx.QueryRowContext(ctx, `
+		SELECT COALESCE(ROUND(AVG(rating), 2), 0), COUNT(*)
+		FROM product_reviews
+		WHERE product_id = $1 AND status = 'approved'
+	`, productID).Scan(&average, &count); err != nil {
+		return fmt.Errorf("compute product rating: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE products
+		SET rating_average = $2, rating_count = $3, rating_updated_at = $4
+		WHERE id = $1 AND (rating_average <> $2 OR rating_count <> $3)
+	`, productID, average, count, now); err != nil {
+		return fmt.Errorf("refresh product rating: %w", err)
+	}
+	return nil
+}
+
+const reviewColumns = `id, product_id, rating, title, body, author_name, verified, status, created_at, moderated_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanReview(row rowScanner) (Review, error) {
+	var rv Review
+	var moderatedAt sql.NullTime
+	if err := row.Scan(&rv.ID, &rv.ProductID, &rv.Rating, &rv.Title, &rv.Body, &rv.AuthorName, &rv.Verified, &rv.Status, &rv.CreatedAt, &moderatedAt); err != nil {
+		return Review{}, err
+	}
+	if moderatedAt.Valid {
+		t := moderatedAt.Time.UTC()
+		rv.ModeratedAt = &t
+	}
+	return rv, nil
+}
+
+func encodeCursor(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+func decodeCursor(raw string) (string, error) {
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return "", ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(string(decoded)); err != nil {
+		return "", ErrInvalidCursor
+	}
+	return string(decoded), nil
+}
+
+var (
+	ErrProductNotFound = errors.New("product not found")
+	ErrAlreadyReviewed = errors.New("order already has a review for this product")
+	ErrInvalidCursor   = errors.New("invalid cursor")
+)